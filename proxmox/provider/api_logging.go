@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+const apiLogSubsystem = "api"
+
+const loginPath = "/access/ticket"
+
+// redactedHeadersRe matches the lines in a dumped request that carry credentials.
+var redactedHeadersRe = regexp.MustCompile(`(?mi)^(Authorization|Cookie|CSRFPreventionToken):.*$`)
+
+// redactedFormFieldsRe matches the fields in a form encoded request body that carry secrets, e.g. the
+// cipassword of a VM, the password of a container or SSH keys.
+var redactedFormFieldsRe = regexp.MustCompile(`(?i)((?:^|&)[\w-]*(?:password|secret|key)[\w-]*=)[^&]*`)
+
+// apiLoggingTransport logs the requests the API client makes through tflog, in the "api" subsystem.
+// The API client can dump requests itself but that's toggled by a package global, shared by every
+// configured provider, and goes to the standard logger. Full dumps are only logged with dump set,
+// otherwise there's one line per request at trace level.
+type apiLoggingTransport struct {
+	ctx       context.Context
+	transport http.RoundTripper
+	dump      bool
+}
+
+func (t *apiLoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fields := map[string]any{"method": req.Method, "url": req.URL.String()}
+	// logging in sends the password and gets a ticket back, neither of which may end up in the log
+	login := strings.HasSuffix(req.URL.Path, loginPath)
+
+	if t.dump {
+		includeBody := req.ContentLength < pveapi.DebugLargeBodyThreshold && !login
+		d, err := httputil.DumpRequestOut(req, includeBody)
+		if err == nil {
+			if !includeBody {
+				d = append(d, fmt.Sprintf("<request body of %d bytes not shown>\n\n", req.ContentLength)...)
+			}
+			d = redactRequestDump(d)
+			tflog.SubsystemDebug(t.ctx, apiLogSubsystem, "API request", withField(fields, "request", string(d)))
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		tflog.SubsystemDebug(t.ctx, apiLogSubsystem, "API request failed", withField(fields, "error", err.Error()))
+		return nil, err
+	}
+	fields["status"] = resp.StatusCode
+
+	if t.dump {
+		includeBody := resp.ContentLength < pveapi.DebugLargeBodyThreshold && !login
+		d, err := httputil.DumpResponse(resp, includeBody)
+		if err == nil {
+			if !includeBody {
+				d = append(d, fmt.Sprintf("<response body of %d bytes not shown>\n\n", resp.ContentLength)...)
+			}
+			tflog.SubsystemDebug(t.ctx, apiLogSubsystem, "API response", withField(fields, "response", string(d)))
+		}
+	} else {
+		tflog.SubsystemTrace(t.ctx, apiLogSubsystem, "API request", fields)
+	}
+
+	return resp, nil
+}
+
+// redactRequestDump returns the dumped request d with credentials in headers and secrets in the body
+// replaced.
+func redactRequestDump(d []byte) []byte {
+	header, body, found := bytes.Cut(d, []byte("\r\n\r\n"))
+	header = redactedHeadersRe.ReplaceAll(header, []byte("$1: <redacted>"))
+	if !found {
+		return header
+	}
+	body = redactedFormFieldsRe.ReplaceAll(body, []byte("$1<redacted>"))
+	return append(append(header, "\r\n\r\n"...), body...)
+}
+
+func withField(fields map[string]any, k string, v any) map[string]any {
+	res := make(map[string]any, len(fields)+1)
+	for fk, fv := range fields {
+		res[fk] = fv
+	}
+	res[k] = v
+	return res
+}
+
+// newAPIHTTPClient builds the HTTP client for the API client, the same one it builds itself when not
+// given one but with requests logged through tflog. ctx is the context logs are written with, it
+// should carry the provider's logger (i.e. come from Configure).
+func newAPIHTTPClient(ctx context.Context, tlsConf *tls.Config, proxyServer string, dump bool) (*http.Client, error) {
+	tr := &http.Transport{
+		TLSClientConfig:    tlsConf,
+		DisableCompression: true,
+	}
+	if proxyServer != "" {
+		proxyURL, err := url.ParseRequestURI(proxyServer)
+		if err != nil {
+			return nil, err
+		}
+		if _, _, err := net.SplitHostPort(proxyURL.Host); err != nil {
+			return nil, err
+		}
+		tr.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &http.Client{
+		Transport: &apiLoggingTransport{
+			ctx:       tflog.NewSubsystem(ctx, apiLogSubsystem),
+			transport: tr,
+			dump:      dump,
+		},
+	}, nil
+}
@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactRequestDump(t *testing.T) {
+	d := "POST /api2/json/nodes/pve/qemu/100/config HTTP/1.1\r\n" +
+		"Host: 127.0.0.1:8806\r\n" +
+		"Authorization: PVEAPIToken=root@pam!tf=897d5216-64c1-4da8-b6dc-33eed34a34a0\r\n" +
+		"\r\n" +
+		"cipassword=hunter2&name=wall-e&sshkeys=ssh-ed25519%20AAAA&password=secret&keyboard=en-us"
+
+	got := string(redactRequestDump([]byte(d)))
+
+	for _, secret := range []string{"897d5216", "hunter2", "AAAA", "secret", "en-us"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("expected %q to be redacted, got:\n%s", secret, got)
+		}
+	}
+	for _, kept := range []string{"Host: 127.0.0.1:8806", "name=wall-e", "cipassword=<redacted>", "sshkeys=<redacted>"} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("expected %q to be kept, got:\n%s", kept, got)
+		}
+	}
+}
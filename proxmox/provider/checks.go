@@ -0,0 +1,305 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+// missingNetBridges returns the bridges referenced by nets that don't exist on node, keyed by NIC id.
+// SDN VNets are included in the node's bridge list so they are accepted as well.
+func missingNetBridges(client *pveapi.Client, node string, nets pveapi.QemuDevices) (map[int]string, error) {
+	if len(nets) == 0 {
+		return nil, nil
+	}
+
+	ifaces, err := client.GetItemListInterfaceArray(fmt.Sprintf("/nodes/%s/network?type=any_bridge", node))
+	if err != nil {
+		return nil, err
+	}
+
+	bridges := map[string]bool{}
+	for _, i := range ifaces {
+		iface, ok := i.(map[string]any)
+		if !ok {
+			continue
+		}
+		if name, ok := iface["iface"].(string); ok {
+			bridges[name] = true
+		}
+	}
+
+	missing := map[int]string{}
+	for id, n := range nets {
+		bridge, ok := n["bridge"].(string)
+		if !ok || bridge == "" {
+			continue
+		}
+		if !bridges[bridge] {
+			missing[id] = bridge
+		}
+	}
+
+	return missing, nil
+}
+
+// checkNetBridges verifies that all bridges referenced by nets exist on node, so that a typo in a
+// bridge name is reported as such instead of as a failed create/update task.
+func checkNetBridges(client *pveapi.Client, node string, nets pveapi.QemuDevices, summary string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	missing, err := missingNetBridges(client, node, nets)
+	if err != nil {
+		diags.AddError(
+			summary,
+			fmt.Sprintf("Could not list network bridges on node '%s', unexpected error: %s", node, err.Error()),
+		)
+		return diags
+	}
+
+	ids := make([]int, 0, len(missing))
+	for id := range missing {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		diags.AddAttributeError(
+			path.Root(netAttributeName(id)).AtName("bridge"),
+			"Network Bridge Not Found",
+			fmt.Sprintf("The bridge %s does not exist on node '%s'. Create the bridge on the node (or the VNet in SDN) first, or check for typos.", missing[id], node),
+		)
+	}
+
+	return diags
+}
+
+// checkDiskStorage verifies that storage exists on node and can hold VM disks, the attribute at p is
+// blamed otherwise. Without this a bad storage only shows up as a failed task.
+func checkDiskStorage(client *pveapi.Client, node string, storage string, p path.Path, summary string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	list, err := client.GetItemListInterfaceArray(fmt.Sprintf("/nodes/%s/storage", node))
+	if err != nil {
+		diags.AddError(
+			summary,
+			fmt.Sprintf("Could not list storages on node '%s', unexpected error: %s", node, err.Error()),
+		)
+		return diags
+	}
+
+	names := []string{}
+	for _, i := range list {
+		s, ok := i.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := s["storage"].(string)
+		content, _ := s["content"].(string)
+		if !strings.Contains(","+content+",", ",images,") {
+			continue
+		}
+		if name == storage {
+			return diags
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	diags.AddAttributeError(
+		p,
+		"Storage Not Found",
+		fmt.Sprintf("There is no storage named '%s' for VM disks on node '%s', available storages are: %s", storage, node, strings.Join(names, ", ")),
+	)
+
+	return diags
+}
+
+// netAttributeName returns the name of the attribute holding the NIC with id, the first one is
+// simply called net.
+func netAttributeName(id int) string {
+	if id == 0 {
+		return "net"
+	}
+	return fmt.Sprintf("net%d", id)
+}
+
+// checkNode verifies that node is a member of the cluster and online, a bad node name or a node
+// that's down otherwise only shows up as a cryptic task failure.
+func checkNode(client *pveapi.Client, node string, summary string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	nodes, err := clusterNodes(client)
+	if err != nil {
+		diags.AddError(
+			summary,
+			"Could not list cluster nodes, unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	online, ok := nodes[node]
+	if !ok {
+		diags.AddAttributeError(
+			path.Root("node"),
+			"Node Not Found",
+			fmt.Sprintf("There is no node named '%s' in the cluster, available nodes are: %s", node, strings.Join(sortedNodeNames(nodes), ", ")),
+		)
+	} else if !online {
+		diags.AddAttributeError(
+			path.Root("node"),
+			"Node Offline",
+			fmt.Sprintf("Node '%s' is not online, it must be reachable by the cluster to manage guests on it.", node),
+		)
+	}
+
+	return diags
+}
+
+// checkClusterNodes is the Configure time counterpart of checkNode. Problems with individual nodes
+// are only warnings here since guests on the other nodes can still be managed, operations against
+// an unusable node fail in checkNode.
+func checkClusterNodes(client *pveapi.Client, defaultNode string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	nodes, err := clusterNodes(client)
+	if err != nil {
+		diags.AddWarning(
+			"Could Not List Cluster Nodes",
+			"Node checks were skipped since the cluster nodes could not be listed, unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	offline := []string{}
+	for _, n := range sortedNodeNames(nodes) {
+		if !nodes[n] {
+			offline = append(offline, n)
+		}
+	}
+	if len(offline) > 0 {
+		diags.AddWarning(
+			"Cluster Nodes Offline",
+			fmt.Sprintf("The following nodes are not online, guests on them can't be managed until they are back: %s", strings.Join(offline, ", ")),
+		)
+	}
+
+	if defaultNode == "" {
+		return diags
+	}
+	if online, ok := nodes[defaultNode]; !ok {
+		diags.AddAttributeWarning(
+			path.Root("default_node"),
+			"Default Node Not Found",
+			fmt.Sprintf("There is no node named '%s' in the cluster, available nodes are: %s. Resources relying on default_node will fail.", defaultNode, strings.Join(sortedNodeNames(nodes), ", ")),
+		)
+	} else if !online {
+		diags.AddAttributeWarning(
+			path.Root("default_node"),
+			"Default Node Offline",
+			fmt.Sprintf("Node '%s' is not online, resources relying on default_node will fail until it is back.", defaultNode),
+		)
+	}
+
+	return diags
+}
+
+// clusterNodes returns the nodes of the cluster, mapped to whether they are online.
+func clusterNodes(client *pveapi.Client) (map[string]bool, error) {
+	list, err := client.GetNodeList()
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := list["data"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("failed to read node list from response: %v", list)
+	}
+
+	nodes := map[string]bool{}
+	for _, d := range data {
+		n, ok := d.(map[string]any)
+		if !ok {
+			continue
+		}
+		if name, ok := n["node"].(string); ok {
+			nodes[name] = n["status"] == "online"
+		}
+	}
+
+	return nodes, nil
+}
+
+func sortedNodeNames(nodes map[string]bool) []string {
+	names := make([]string, 0, len(nodes))
+	for n := range nodes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lockWaitTimeout is how long deleting a guest waits for a lock, e.g. from a backup job, to clear.
+const lockWaitTimeout = 5 * time.Minute
+
+// checkDeletable verifies that the guest behind vmr can be deleted. Protection is reported right away
+// since it never clears by itself, a lock is waited out for up to lockWaitTimeout first. Without this
+// both only show up as a failed delete task.
+func checkDeletable(ctx context.Context, client *pveapi.Client, vmr *pveapi.VmRef, summary string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	deadline := time.Now().Add(lockWaitTimeout)
+	for {
+		config, err := client.GetVmConfig(vmr)
+		if err != nil {
+			diags.AddError(
+				summary,
+				fmt.Sprintf("Could not read config of guest %d before deleting, unexpected error: %s", vmr.VmId(), err.Error()),
+			)
+			return diags
+		}
+
+		// the guest type is known once the config has been read
+		kind, cli := "VM", "qm"
+		if vmr.GetVmType() == vmTypeLxc {
+			kind, cli = "LXC", "pct"
+		}
+
+		if protection, ok := config["protection"].(float64); ok && protection == 1 {
+			diags.AddError(
+				summary,
+				fmt.Sprintf("%s %d has protection enabled, which prevents it from being deleted. Turn off protection on the guest and try again.", kind, vmr.VmId()),
+			)
+			return diags
+		}
+
+		lock, ok := config["lock"].(string)
+		if !ok || lock == "" {
+			return diags
+		}
+		if time.Now().After(deadline) {
+			diags.AddError(
+				summary,
+				fmt.Sprintf("%s %d is still locked (%s) after waiting %s. Wait for the operation holding the lock to finish, or if it's stale remove it with '%s unlock %d', and try again.", kind, vmr.VmId(), lock, lockWaitTimeout, cli, vmr.VmId()),
+			)
+			return diags
+		}
+
+		tflog.Info(ctx, fmt.Sprintf("%s %d is locked (%s), waiting for the lock to clear before deleting", kind, vmr.VmId(), lock))
+		select {
+		case <-ctx.Done():
+			diags.AddError(
+				summary,
+				fmt.Sprintf("Cancelled while waiting for lock (%s) on %s %d to clear.", lock, kind, vmr.VmId()),
+			)
+			return diags
+		case <-time.After(taskPollInterval):
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// PVE stores the description of a guest as comment lines in its config file, so what's read back
+// isn't byte for byte what was sent: line endings come back as \n and trailing whitespace, on the
+// whole and on each line, may be dropped. The helpers here compare descriptions the way PVE stores
+// them so that multi-line notes round-trip without diffs, for all guest types alike.
+
+// normalizeDescription returns description the way PVE would store it.
+func normalizeDescription(description string) string {
+	lines := strings.Split(strings.ReplaceAll(description, "\r\n", "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t\r")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// descriptionValue returns the description read from a guest config as a value for the model, null
+// if there is none. If it's the current value as stored by PVE the current value is kept, so that a
+// description written with e.g. \r\n line endings or a trailing newline doesn't show up as a diff, and
+// neither does description = "" which PVE stores as no description at all.
+func descriptionValue(current types.String, description string) types.String {
+	description = normalizeDescription(description)
+	if !current.IsNull() && !current.IsUnknown() && normalizeDescription(current.ValueString()) == description {
+		return current
+	}
+	if description == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(description)
+}
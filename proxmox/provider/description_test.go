@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDescriptionValue(t *testing.T) {
+	for _, tc := range []struct {
+		current     types.String
+		description string
+		expected    types.String
+	}{
+		{types.StringNull(), "", types.StringNull()},
+		{types.StringNull(), "wall-e\n", types.StringValue("wall-e")},
+		{types.StringValue(""), "", types.StringValue("")},
+		{types.StringValue("wall-e\r\n"), "wall-e\n", types.StringValue("wall-e\r\n")},
+		{types.StringValue(""), "eve\n", types.StringValue("eve")},
+		{types.StringValue("wall-e"), "", types.StringNull()},
+		{types.StringUnknown(), "", types.StringNull()},
+	} {
+		got := descriptionValue(tc.current, tc.description)
+		if !got.Equal(tc.expected) {
+			t.Errorf("expected %s for current %s and description %q, got %s", tc.expected, tc.current, tc.description, got)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// withGuestContext prefixes the details of the errors and warnings in diags with the guest they're
+// about, so that failures can be told apart when many guests are in the same plan. vmid is 0 when it
+// isn't known yet, e.g. when creating a guest fails before an ID has been picked.
+func withGuestContext(diags diag.Diagnostics, kind string, node string, vmid int64) diag.Diagnostics {
+	if len(diags) == 0 {
+		return diags
+	}
+
+	prefix := "new " + kind
+	if vmid != 0 {
+		prefix = fmt.Sprintf("%s %d", kind, vmid)
+	}
+	if node != "" {
+		prefix += " on node " + node
+	}
+
+	res := make(diag.Diagnostics, 0, len(diags))
+	for _, d := range diags {
+		detail := prefix + ": " + d.Detail()
+		switch {
+		case d.Severity() == diag.SeverityError:
+			if p, ok := d.(diag.DiagnosticWithPath); ok {
+				d = diag.NewAttributeErrorDiagnostic(p.Path(), d.Summary(), detail)
+			} else {
+				d = diag.NewErrorDiagnostic(d.Summary(), detail)
+			}
+		case d.Severity() == diag.SeverityWarning:
+			if p, ok := d.(diag.DiagnosticWithPath); ok {
+				d = diag.NewAttributeWarningDiagnostic(p.Path(), d.Summary(), detail)
+			} else {
+				d = diag.NewWarningDiagnostic(d.Summary(), detail)
+			}
+		}
+		res = append(res, d)
+	}
+	return res
+}
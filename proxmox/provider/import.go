@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+// importGuest implements ImportState for the guest resources. The import id is either "<node>/<vmid>"
+// or just "<vmid>". The guest is looked up to make sure it exists and is of vmType, only node and vmid
+// (and ignore_node_drift) are set in state and the resource's Read fills in the rest.
+func importGuest(ctx context.Context, client *pveapi.Client, vmType string, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	kind := "VM"
+	if vmType == vmTypeLxc {
+		kind = "LXC"
+	}
+	summary := fmt.Sprintf("Error Importing %s", kind)
+
+	node, id, hasNode := strings.Cut(req.ID, "/")
+	if !hasNode {
+		node, id = "", node
+	}
+	vmid, err := strconv.Atoi(id)
+	if err != nil || vmid <= 0 || (hasNode && node == "") {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: <node>/<vmid> or <vmid>. Got: %q", req.ID),
+		)
+		return
+	}
+
+	vmr := pveapi.NewVmRef(vmid)
+	err = client.CheckVmRef(vmr)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			summary,
+			fmt.Sprintf("Could not look up %s %d, unexpected error: %s", kind, vmid, err.Error()),
+		)
+		return
+	}
+
+	if vmr.GetVmType() != vmType {
+		resp.Diagnostics.AddError(
+			summary,
+			fmt.Sprintf("Guest %d is not a %s (it's of type %s), import it as the matching resource instead.", vmid, kind, vmr.GetVmType()),
+		)
+		return
+	}
+	if node != "" && vmr.Node() != node {
+		resp.Diagnostics.AddError(
+			summary,
+			fmt.Sprintf("%s %d is on node %s, not %s.", kind, vmid, vmr.Node(), node),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("node"), vmr.Node())...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("vmid"), int64(vmid))...)
+	// only lives in state, start out with the default
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ignore_node_drift"), false)...)
+}
@@ -4,16 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -30,6 +35,7 @@ var (
 	_ resource.Resource                = &lxcResource{}
 	_ resource.ResourceWithConfigure   = &lxcResource{}
 	_ resource.ResourceWithImportState = &lxcResource{}
+	_ resource.ResourceWithModifyPlan  = &lxcResource{}
 )
 
 func NewLXCResource() resource.Resource {
@@ -37,18 +43,32 @@ func NewLXCResource() resource.Resource {
 }
 
 type lxcResource struct {
-	client *pveapi.Client
+	client      *pveapi.Client
+	defaultNode string
 }
 
 type lxcResourceModel struct {
-	Node types.String `tfsdk:"node"`
-	VMID types.Int64  `tfsdk:"vmid"`
+	Node            types.String `tfsdk:"node"`
+	IgnoreNodeDrift types.Bool   `tfsdk:"ignore_node_drift"`
+	VMID            types.Int64  `tfsdk:"vmid"`
 
-	Status types.String `tfsdk:"status"`
+	Status      types.String `tfsdk:"status"`
+	Onboot      types.Bool   `tfsdk:"onboot"`
+	Startup     types.String `tfsdk:"startup"`
+	Pool        types.String `tfsdk:"pool"`
+	Tags        types.Set    `tfsdk:"tags"`
+	Description types.String `tfsdk:"description"`
 
 	Ostemplate   types.String `tfsdk:"ostemplate"`
 	Unprivileged types.Bool   `tfsdk:"unprivileged"`
 	Ostype       types.String `tfsdk:"ostype"`
+	Features     types.Object `tfsdk:"features"`
+
+	Cores    types.Int64 `tfsdk:"cores"`
+	CPULimit types.Int64 `tfsdk:"cpulimit"`
+	CPUUnits types.Int64 `tfsdk:"cpuunits"`
+	Memory   types.Int64 `tfsdk:"memory"`
+	Swap     types.Int64 `tfsdk:"swap"`
 
 	Hostname      types.String `tfsdk:"hostname"`
 	Password      types.String `tfsdk:"password"`
@@ -56,6 +76,17 @@ type lxcResourceModel struct {
 
 	RootFs types.Object `tfsdk:"rootfs"`
 
+	Mp0 types.Object `tfsdk:"mp0"`
+	Mp1 types.Object `tfsdk:"mp1"`
+	Mp2 types.Object `tfsdk:"mp2"`
+	Mp3 types.Object `tfsdk:"mp3"`
+	Mp4 types.Object `tfsdk:"mp4"`
+	Mp5 types.Object `tfsdk:"mp5"`
+	Mp6 types.Object `tfsdk:"mp6"`
+	Mp7 types.Object `tfsdk:"mp7"`
+	Mp8 types.Object `tfsdk:"mp8"`
+	Mp9 types.Object `tfsdk:"mp9"`
+
 	Net types.Object `tfsdk:"net"`
 }
 
@@ -74,15 +105,13 @@ func (rootfsModel) AttributeTypes() map[string]attr.Type {
 }
 
 func (m *rootfsModel) readFromAPIConfig(c *pveapi.QemuDevice) {
-	if val, ok := (*c)["volume"]; ok && val != "" {
-		m.Volume = types.StringValue(val.(string))
-		parts := strings.Split(val.(string), ":")
-		m.Storage = types.StringValue(parts[0])
-		if len(parts) == 2 {
-			size, err := strconv.ParseInt(parts[1], 10, 64)
-			if err == nil { // if eg "local-lvm:3" read it as 3G size
-				m.Size = types.StringValue(fmt.Sprintf("%dG", size))
-			}
+	if val, ok := (*c)["volume"].(string); ok && val != "" {
+		volid, size := parseRootfsVolume(val)
+		m.Volume = types.StringValue(volid)
+		storage, _, _ := strings.Cut(volid, ":")
+		m.Storage = types.StringValue(storage)
+		if size != "" {
+			m.Size = types.StringValue(size)
 		}
 	} else if val, ok := (*c)["storage"]; ok {
 		m.Storage = types.StringValue(val.(string))
@@ -92,6 +121,28 @@ func (m *rootfsModel) readFromAPIConfig(c *pveapi.QemuDevice) {
 	}
 }
 
+// parseRootfsVolume splits a volume like "local-lvm:vm-100-disk-0,size=8G" into the volume ID and its
+// size. A volume being allocated, like "local-lvm:3", is read as having size 3G.
+func parseRootfsVolume(s string) (volid string, size string) {
+	parts := strings.Split(s, ",")
+	volid = parts[0]
+	for _, opt := range parts[1:] {
+		if k, v, ok := strings.Cut(opt, "="); ok && k == "size" {
+			size = v
+		}
+	}
+
+	if size == "" {
+		if _, alloc, ok := strings.Cut(volid, ":"); ok {
+			if n, err := strconv.ParseInt(alloc, 10, 64); err == nil {
+				size = fmt.Sprintf("%dG", n)
+			}
+		}
+	}
+
+	return volid, size
+}
+
 func (m rootfsModel) writeToAPIConfig(c *pveapi.QemuDevice) {
 	(*c)["size"] = m.Size.ValueString()
 	if !m.Volume.IsUnknown() {
@@ -103,6 +154,84 @@ func (m rootfsModel) writeToAPIConfig(c *pveapi.QemuDevice) {
 	}
 }
 
+type mountpointModel struct {
+	Volume   types.String `tfsdk:"volume"`
+	Storage  types.String `tfsdk:"storage"`
+	Size     types.String `tfsdk:"size"`
+	MP       types.String `tfsdk:"mp"`
+	ReadOnly types.Bool   `tfsdk:"readonly"`
+	Backup   types.Bool   `tfsdk:"backup"`
+}
+
+func (mountpointModel) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"volume":   types.StringType,
+		"storage":  types.StringType,
+		"size":     types.StringType,
+		"mp":       types.StringType,
+		"readonly": types.BoolType,
+		"backup":   types.BoolType,
+	}
+}
+
+func (m *mountpointModel) readFromAPIConfig(c *pveapi.QemuDevice) {
+	if val, ok := (*c)["volume"].(string); ok && val != "" {
+		m.Volume = types.StringValue(val)
+	}
+	// a bind mounted host path has neither storage nor size
+	if val, ok := (*c)["storage"].(string); ok && val != "" {
+		m.Storage = types.StringValue(val)
+	}
+	if val, ok := (*c)["size"].(string); ok {
+		m.Size = types.StringValue(val)
+	}
+	if val, ok := (*c)["mp"].(string); ok {
+		m.MP = types.StringValue(val)
+	}
+	// flags are read as ints by the API client, PVE leaves mount points out of backups unless backup=1
+	ro, _ := (*c)["ro"].(int)
+	m.ReadOnly = types.BoolValue(ro == 1)
+	backup, _ := (*c)["backup"].(int)
+	m.Backup = types.BoolValue(backup == 1)
+}
+
+func (m mountpointModel) writeToAPIConfig(c *pveapi.QemuDevice, slot int) {
+	(*c)["slot"] = slot
+	if m.isBindMount() {
+		// the host path is passed on as is, there's nothing to allocate, move or resize
+		(*c)["volume"] = m.Volume.ValueString()
+		(*c)["storage"] = ""
+		(*c)["size"] = ""
+	} else {
+		(*c)["size"] = m.Size.ValueString()
+		// storage as configured, applyLxcDiskChanges moves the volume when it differs from the current one
+		(*c)["storage"] = m.Storage.ValueString()
+		// an empty volume is allocated on storage, and isn't taken as a different volume by applyLxcDiskChanges
+		// when it's unknown since it's being moved
+		(*c)["volume"] = ""
+		if !m.Volume.IsUnknown() && !m.Volume.IsNull() {
+			(*c)["volume"] = m.Volume.ValueString()
+		}
+	}
+	(*c)["mp"] = m.MP.ValueString()
+	// always set, false isn't sent but keeps applyLxcDiskChanges from carrying over a previous ro=1
+	(*c)["ro"] = m.ReadOnly.ValueBool()
+	if !m.Backup.IsNull() && !m.Backup.IsUnknown() {
+		(*c)["backup"] = m.Backup.ValueBool()
+	}
+}
+
+// isBindMount returns whether the mount point is a host path bind mounted into the container.
+func (m mountpointModel) isBindMount() bool {
+	return isBindMountVolume(m.Volume.ValueString())
+}
+
+// isBindMountVolume returns whether volume is a host path rather than a volume on storage.
+func isBindMountVolume(volume any) bool {
+	s, _ := volume.(string)
+	return strings.HasPrefix(s, "/")
+}
+
 type lxcNetModel struct {
 	Name    types.String `tfsdk:"name"`
 	Bridge  types.String `tfsdk:"bridge"`
@@ -143,6 +272,51 @@ func (m lxcNetModel) writeToAPIConfig(c *pveapi.QemuDevice) {
 	}
 }
 
+type lxcFeaturesModel struct {
+	Nesting types.Bool   `tfsdk:"nesting"`
+	Keyctl  types.Bool   `tfsdk:"keyctl"`
+	Fuse    types.Bool   `tfsdk:"fuse"`
+	Mount   types.String `tfsdk:"mount"`
+}
+
+func (lxcFeaturesModel) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"nesting": types.BoolType,
+		"keyctl":  types.BoolType,
+		"fuse":    types.BoolType,
+		"mount":   types.StringType,
+	}
+}
+
+func (m *lxcFeaturesModel) readFromAPIConfig(c pveapi.QemuDevice) {
+	// flags are read as ints by the API client
+	nesting, _ := c["nesting"].(int)
+	m.Nesting = types.BoolValue(nesting == 1)
+	keyctl, _ := c["keyctl"].(int)
+	m.Keyctl = types.BoolValue(keyctl == 1)
+	fuse, _ := c["fuse"].(int)
+	m.Fuse = types.BoolValue(fuse == 1)
+	if val, ok := c["mount"].(string); ok && val != "" {
+		m.Mount = types.StringValue(val)
+	} else {
+		m.Mount = types.StringNull()
+	}
+}
+
+// writeToAPIConfig sets the enabled features in c, the API client leaves out the ones turned off.
+func (m lxcFeaturesModel) writeToAPIConfig(c *pveapi.QemuDevice) {
+	(*c)["nesting"] = m.Nesting.ValueBool()
+	(*c)["keyctl"] = m.Keyctl.ValueBool()
+	(*c)["fuse"] = m.Fuse.ValueBool()
+	if !m.Mount.IsNull() && !m.Mount.IsUnknown() {
+		(*c)["mount"] = m.Mount.ValueString()
+	}
+}
+
+func (m lxcFeaturesModel) anyEnabled() bool {
+	return m.Nesting.ValueBool() || m.Keyctl.ValueBool() || m.Fuse.ValueBool() || m.Mount.ValueString() != ""
+}
+
 type LXCStateMask uint8
 
 const (
@@ -161,11 +335,21 @@ func (*lxcResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *re
 		Description: "This resource manages a Proxmox LXC.",
 		Attributes: map[string]schema.Attribute{
 			"node": schema.StringAttribute{
-				Description: "The cluster node name.",
-				Required:    true,
+				Description: "The cluster node name. Defaults to default_node of the provider.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					IgnoreNodeDrift(),
+				},
+			},
+			"ignore_node_drift": schema.BoolAttribute{
+				Description: "Don't try to move the guest back when it's found on another node than configured, e.g. after being migrated by HA. Changes to node are ignored altogether while set.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
 			},
 			"vmid": schema.Int64Attribute{
-				Description: "The (unique) ID of the VM.",
+				Description: "The (unique) ID of the container. If left out the next free ID is used, should another run take it first a new one is picked, up to 10 attempts. An ID that's left out, or removed from config, is kept only for as long as the container exists: if it's recreated, e.g. after being destroyed outside of Terraform, it gets the next free ID. Set it to keep the ID stable.",
 				Computed:    true,
 				Optional:    true,
 				PlanModifiers: []planmodifier.Int64{
@@ -181,11 +365,41 @@ func (*lxcResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *re
 					stringvalidator.OneOf([]string{stateStopped, stateRunning}...),
 				},
 			},
+			"onboot": schema.BoolAttribute{
+				Description: "Specifies whether the container will be started during system bootup.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"startup": schema.StringAttribute{
+				Description: "Startup and shutdown behavior, e.g. \"order=2,up=30,down=60\". Order is a non-negative number defining the general startup order, shutdown is done in reverse order. Up and down are startup and shutdown delays in seconds.",
+				Optional:    true,
+				Validators: []validator.String{
+					StartupValidator("value must be a comma separated list of order=<n>, up=<seconds> and down=<seconds> options"),
+				},
+			},
+			"pool": schema.StringAttribute{
+				Description: "Resource pool to put the container in, the pool must already exist. Changing the pool moves the container to the new pool.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"tags": schema.SetAttribute{
+				Description: "Tags of the container, e.g. [\"prod\", \"web\"]. Tags are only meta information, they can be changed without touching the guest.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators:  TagsValidators(),
+			},
+			"description": schema.StringAttribute{
+				Description: "Description for the container. Shown in the web-interface container's summary, it can be markdown and span multiple lines.",
+				Optional:    true,
+			},
 			"ostemplate": schema.StringAttribute{
 				Description: "The OS template or backup file.",
 				Required:    true,
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					RequiresReplaceUnlessImported(path.Root("ostemplate")),
 				},
 			},
 			"unprivileged": schema.BoolAttribute{
@@ -197,6 +411,7 @@ func (*lxcResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *re
 					boolplanmodifier.RequiresReplace(),
 				},
 			},
+			"features": schemaLxcFeatures(),
 			"ostype": schema.StringAttribute{
 				Description: "OS type. This is used to setup configuration inside the container, and corresponds to lxc setup scripts in /usr/share/lxc/config/<ostype>.common.conf. Value 'unmanaged' can be used to skip OS specific setup.",
 				Computed:    true,
@@ -205,10 +420,63 @@ func (*lxcResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *re
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"cores": schema.Int64Attribute{
+				Description: "The number of cores assigned to the container. A container can use all available cores by default.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+				Validators: []validator.Int64{
+					int64validator.Between(1, 8192),
+				},
+			},
+			"cpulimit": schema.Int64Attribute{
+				Description: "Limit of CPU usage, 0 means no limit.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(0),
+				Validators: []validator.Int64{
+					int64validator.Between(0, 8192),
+				},
+			},
+			"cpuunits": schema.Int64Attribute{
+				Description: "CPU weight for the container, relative to the weights of all other running guests.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+				Validators: []validator.Int64{
+					int64validator.Between(0, 500000),
+				},
+			},
+			"memory": schema.Int64Attribute{
+				Description: "Amount of RAM for the container in MB. This is a hard limit (the cgroup memory limit), PVE has no soft limit or reservation for containers.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(512),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(16),
+				},
+			},
+			"swap": schema.Int64Attribute{
+				Description: "Amount of SWAP for the container in MB, on top of memory, requires memory to be set.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(512),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+					int64validator.AlsoRequires(path.MatchRoot("memory")),
+				},
+			},
 			"hostname": schema.StringAttribute{
-				Description: "Set a host name for the container.",
+				Description: "Set a host name for the container. Renaming is done in place, removing it from config keeps the current host name.",
 				Computed:    true,
 				Optional:    true,
+				Validators: []validator.String{
+					DNSNameValidator("value must be a valid DNS name, i.e. dot separated labels of letters, digits and hyphens, each 1-63 characters and not starting or ending with a hyphen"),
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
@@ -219,7 +487,7 @@ func (*lxcResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *re
 				Sensitive:   true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
-					stringplanmodifier.RequiresReplace(),
+					RequiresReplaceUnlessImported(path.Root("ostemplate")),
 				},
 			},
 			"ssh_public_keys": schema.StringAttribute{
@@ -227,10 +495,20 @@ func (*lxcResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *re
 				Optional:    true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
-					stringplanmodifier.RequiresReplace(),
+					RequiresReplaceUnlessImported(path.Root("ostemplate")),
 				},
 			},
 			"rootfs": schemaRootFs(),
+			"mp0":    schemaMountpoint(),
+			"mp1":    schemaMountpoint(),
+			"mp2":    schemaMountpoint(),
+			"mp3":    schemaMountpoint(),
+			"mp4":    schemaMountpoint(),
+			"mp5":    schemaMountpoint(),
+			"mp6":    schemaMountpoint(),
+			"mp7":    schemaMountpoint(),
+			"mp8":    schemaMountpoint(),
+			"mp9":    schemaMountpoint(),
 			"net":    schemaLxcNet(),
 		},
 	}
@@ -254,7 +532,7 @@ func schemaRootFs() schema.Attribute {
 				Description: "Size in kilobyte (1024 bytes). Optional suffixes 'M' (megabyte, 1024K) and 'G' (gigabyte, 1024M)",
 				Required:    true,
 				Validators: []validator.String{
-					DiskSizeValidator("size must be numbers only, possibly ending in M or G"),
+					DiskSizeValidator("size must be numbers only, possibly ending in K, M or G"),
 				},
 			},
 		},
@@ -264,6 +542,97 @@ func schemaRootFs() schema.Attribute {
 	}
 }
 
+func schemaMountpoint() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Description: "Use volume as container mount point. Either a new volume is allocated on storage, removing the mount point leaves the volume as an unused disk of the container, or a host path given as volume is bind mounted.",
+		Optional:    true,
+		Attributes: map[string]schema.Attribute{
+			"volume": schema.StringAttribute{
+				Description: "Volume identifier. Set it to an absolute path on the host, e.g. /mnt/data, to bind mount that path instead of allocating a volume on storage. Bind mounts can only be set up by root@pam.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					// a volume moved to another storage gets a new identifier
+					UseStateForUnknownUnlessSiblingChanges("storage"),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^/`), "must be an absolute path on the host"),
+					stringvalidator.ConflictsWith(
+						path.MatchRelative().AtParent().AtName("storage"),
+						path.MatchRelative().AtParent().AtName("size"),
+					),
+				},
+			},
+			"storage": schema.StringAttribute{
+				Description: "The storage identifier, required unless volume is a host path. Changing it moves the volume to the new storage, which requires stopping the container.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.AtLeastOneOf(path.MatchRelative().AtParent().AtName("volume")),
+					stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("size")),
+				},
+			},
+			"size": schema.StringAttribute{
+				Description: "Size in kilobyte (1024 bytes), required with storage. Optional suffixes 'M' (megabyte, 1024K) and 'G' (gigabyte, 1024M)",
+				Optional:    true,
+				Validators: []validator.String{
+					DiskSizeValidator("size must be numbers only, possibly ending in K, M or G"),
+					stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("storage")),
+				},
+			},
+			"mp": schema.StringAttribute{
+				Description: "Path to the mount point as seen from inside the container, e.g. /data.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^/`), "must be an absolute path"),
+				},
+			},
+			"readonly": schema.BoolAttribute{
+				Description: "Mount the volume read-only.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"backup": schema.BoolAttribute{
+				Description: "Include the volume in backups of the container.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+		},
+	}
+}
+
+func schemaLxcFeatures() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Description: "Allow containers access to advanced features. Changing them on a running container reboots it.",
+		Optional:    true,
+		Attributes: map[string]schema.Attribute{
+			"nesting": schema.BoolAttribute{
+				Description: "Allow nesting, e.g. to run Docker in the container. Best used with unprivileged containers with additional id mapping.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"keyctl": schema.BoolAttribute{
+				Description: "For unprivileged containers only: Allow the use of the keyctl() system call. This is required to use docker inside a container.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"fuse": schema.BoolAttribute{
+				Description: "Allow using 'fuse' file systems in a container.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"mount": schema.StringAttribute{
+				Description: "Allow mounting file systems of specific types, as a list separated by semicolons, e.g. \"nfs;cifs\".",
+				Optional:    true,
+			},
+		},
+	}
+}
+
 func schemaLxcNet() schema.Attribute {
 	return schema.SingleNestedAttribute{
 		Description: "Specifies the network interface for the container.",
@@ -303,17 +672,26 @@ func (r *lxcResource) Configure(_ context.Context, req resource.ConfigureRequest
 		return
 	}
 
-	client, ok := req.ProviderData.(*pveapi.Client)
+	data, ok := req.ProviderData.(*proxmoxProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Provider Data Type",
-			fmt.Sprintf("Expected %T, got: %T. Please report this to the provider developers.", client, req.ProviderData),
+			fmt.Sprintf("Expected %T, got: %T. Please report this to the provider developers.", data, req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.client
+	r.defaultNode = data.defaultNode
+}
+
+func (r *lxcResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// provider isn't configured yet, e.g. its config depends on values not known until apply
+	if r.client == nil {
+		return
+	}
+	resp.Diagnostics.Append(planDefaultNode(ctx, req, resp, r.defaultNode)...)
 }
 
 func (r *lxcResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -324,6 +702,15 @@ func (r *lxcResource) Create(ctx context.Context, req resource.CreateRequest, re
 		return
 	}
 
+	var vmr *pveapi.VmRef
+	defer func() {
+		vmid := plan.VMID.ValueInt64()
+		if vmr != nil {
+			vmid = int64(vmr.VmId())
+		}
+		resp.Diagnostics = withGuestContext(resp.Diagnostics, "LXC", plan.Node.ValueString(), vmid)
+	}()
+
 	config := &pveapi.ConfigLxc{}
 	err := apiConfigFromLXCResourceModel(ctx, &plan, config)
 	if err != nil {
@@ -334,8 +721,21 @@ func (r *lxcResource) Create(ctx context.Context, req resource.CreateRequest, re
 	}
 	tflog.Trace(ctx, fmt.Sprintf("Creating LXC from model: %+v", plan))
 
-	var vmr *pveapi.VmRef
+	resp.Diagnostics.Append(checkNode(r.client, plan.Node.ValueString(), "Error Creating LXC")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(checkNetBridges(r.client, plan.Node.ValueString(), config.Networks, "Error Creating LXC")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// mount points are added once the container exists, the API client can't create them with backup=1
+	mps := config.Mountpoints
+	config.Mountpoints = nil
 
+	collisions := 0
 	for {
 		id, err := getIDToUse(plan.VMID, r.client)
 		if err != nil {
@@ -353,8 +753,19 @@ func (r *lxcResource) Create(ctx context.Context, req resource.CreateRequest, re
 		if err != nil {
 			re := regexp.MustCompile(`unable to create CT \d+ \- CT \d+ already exists`)
 			if plan.VMID.IsUnknown() && re.MatchString(err.Error()) {
-				// if we tried creating with an auto-assigned ID try again
-				continue
+				collisions++
+				if collisions < maxIDAttempts {
+					// if we tried creating with an auto-assigned ID try again
+					tflog.Trace(ctx, fmt.Sprintf("VMID %d was taken, retrying with a new one", id))
+					if err := waitIDCollisionBackoff(ctx, collisions); err != nil {
+						resp.Diagnostics.AddError(
+							"Error Creating LXC",
+							"Interrupted while waiting to retry with a new VM ID: "+err.Error(),
+						)
+						return
+					}
+					continue
+				}
 			}
 
 			resp.Diagnostics.AddError(
@@ -367,6 +778,28 @@ func (r *lxcResource) Create(ctx context.Context, req resource.CreateRequest, re
 		break
 	}
 
+	if len(mps) > 0 {
+		err = applyLxcDiskChanges(pveapi.QemuDevices{}, mps, vmr, r.client)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Creating LXC",
+				"Could not add mount points to created LXC, unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	if params := lxcZeroValueParams(&plan); len(params) > 0 {
+		_, err = r.client.SetLxcConfig(vmr, params)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Creating LXC",
+				"Could not set zero valued options on created LXC, unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
 	if plan.Status.ValueString() == stateRunning {
 		tflog.Trace(ctx, "Starting LXC since status set to "+plan.Status.ValueString())
 		_, err := r.client.StartVm(vmr)
@@ -404,6 +837,9 @@ func (r *lxcResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	defer func() {
+		resp.Diagnostics = withGuestContext(resp.Diagnostics, "LXC", state.Node.ValueString(), state.VMID.ValueInt64())
+	}()
 
 	if !state.VMID.IsUnknown() {
 		tflog.Trace(ctx, fmt.Sprintf("Reading state for LXC %d", state.VMID.ValueInt64()))
@@ -419,7 +855,7 @@ func (r *lxcResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 
 		vmExists := false
 		for _, vm := range vms {
-			if int64(vm.Id) == state.VMID.ValueInt64() {
+			if int64(vm.Id) == state.VMID.ValueInt64() && vm.Type == pveapi.GuestLXC {
 				vmExists = true
 				break
 			}
@@ -463,6 +899,9 @@ func (r *lxcResource) Update(ctx context.Context, req resource.UpdateRequest, re
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	defer func() {
+		resp.Diagnostics = withGuestContext(resp.Diagnostics, "LXC", plan.Node.ValueString(), state.VMID.ValueInt64())
+	}()
 
 	tflog.Trace(ctx, fmt.Sprintf("Updating LXC with plan: %+v", plan))
 
@@ -486,6 +925,56 @@ func (r *lxcResource) Update(ctx context.Context, req resource.UpdateRequest, re
 	vmr.SetNode(plan.Node.ValueString())
 	vmr.SetVmType(vmTypeLxc)
 
+	resp.Diagnostics.Append(checkNode(r.client, plan.Node.ValueString(), "Error Updating LXC")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(checkNetBridges(r.client, plan.Node.ValueString(), config.Networks, "Error Updating LXC")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	requiresStop, err := lxcChangesRequireStop(ctx, &state, &plan)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error constructing API struct from internal model",
+			"This is a provider bug. Please report it to the developers.\n\n"+err.Error())
+		return
+	}
+
+	restart := false
+	if requiresStop {
+		var current lxcResourceModel
+		err = UpdateLXCResourceModelFromAPI(ctx, id, r.client, &current, LXCStateStatus)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating LXC",
+				"Could not read LXC status before updating it, unexpected error: "+err.Error(),
+			)
+			return
+		}
+
+		if current.Status.ValueString() == stateRunning {
+			tflog.Trace(ctx, fmt.Sprintf("Stopping LXC %d since the changes can only be applied while it is stopped", id))
+			_, err = r.client.StopVm(vmr)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Updating LXC",
+					"Could not stop LXC before updating it, unexpected error: "+err.Error(),
+				)
+				return
+			}
+			restart = true
+			// bring it back to the state we found it in also when updating it fails part way
+			defer func() {
+				if restart {
+					resp.Diagnostics.Append(startLXCAfterUpdate(ctx, r.client, vmr)...)
+				}
+			}()
+		}
+	}
+
 	if state.RootFs.IsNull() != plan.RootFs.IsNull() || !state.RootFs.Equal(plan.RootFs) {
 		oldRootfs, err := rootfsAPIConfigFromStateValue(ctx, state.RootFs)
 		if err != nil {
@@ -516,6 +1005,52 @@ func (r *lxcResource) Update(ctx context.Context, req resource.UpdateRequest, re
 		config.RootFs = newRootfs
 	}
 
+	oldMps, err := mountpointsAPIConfigFromModel(ctx, &state)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error constructing API struct from internal model",
+			"This is a provider bug. Please report it to the developers.\n\n"+err.Error())
+		return
+	}
+	newMps := config.Mountpoints
+	// mount points are set here rather than by UpdateConfig, the API client can't send backup=1
+	config.Mountpoints = nil
+	if !reflect.DeepEqual(oldMps, newMps) {
+		// options of existing mount points are compared before applyLxcDiskChanges merges in the previous ones
+		optionsChanged := map[int]bool{}
+		for slot, next := range newMps {
+			if prev, ok := oldMps[slot]; ok {
+				optionsChanged[slot] = prev["mp"] != next["mp"] || prev["ro"] != next["ro"] || prev["backup"] != next["backup"]
+			}
+		}
+
+		err = applyLxcDiskChanges(oldMps, newMps, vmr, r.client)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating LXC",
+				"Could not update LXC mount points, unexpected error: "+err.Error(),
+			)
+			return
+		}
+
+		mpParams := map[string]any{}
+		for slot, next := range newMps {
+			if optionsChanged[slot] {
+				mpParams[diskSlotName(next)] = formatMountpointParam(next)
+			}
+		}
+		if len(mpParams) > 0 {
+			_, err = r.client.SetLxcConfig(vmr, mpParams)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Updating LXC",
+					"Could not update LXC mount point options, unexpected error: "+err.Error(),
+				)
+				return
+			}
+		}
+	}
+
 	err = config.UpdateConfig(vmr, r.client)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -524,8 +1059,68 @@ func (r *lxcResource) Update(ctx context.Context, req resource.UpdateRequest, re
 		)
 		return
 	}
+
+	if params := lxcZeroValueParams(&plan); len(params) > 0 {
+		_, err = r.client.SetLxcConfig(vmr, params)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating LXC",
+				"Could not set zero valued options on LXC, unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+	// the API client only ever sets options, startup, description, tags and features need to be deleted explicitly
+	deletes := []string{}
+	if plan.Startup.IsNull() && !state.Startup.IsNull() {
+		deletes = append(deletes, "startup")
+	}
+	if plan.Description.ValueString() == "" && state.Description.ValueString() != "" {
+		deletes = append(deletes, "description")
+	}
+	if plan.Tags.IsNull() && !state.Tags.IsNull() {
+		deletes = append(deletes, "tags")
+	}
+	priorFeatures, err := lxcFeaturesAPIConfigFromStateValue(ctx, state.Features)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error constructing API struct from internal model",
+			"This is a provider bug. Please report it to the developers.\n\n"+err.Error())
+		return
+	}
+	if config.Features == nil && priorFeatures != nil {
+		deletes = append(deletes, "features")
+	}
+	if len(deletes) > 0 {
+		_, err = r.client.SetLxcConfig(vmr, map[string]any{"delete": strings.Join(deletes, ",")})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating LXC",
+				"Could not remove "+strings.Join(deletes, ", ")+" from LXC, unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+	// the API client doesn't update the pool at all
+	err = moveGuestToPool(r.client, id, state.Pool.ValueString(), plan.Pool.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating LXC",
+			"Could not move LXC to pool, unexpected error: "+err.Error(),
+		)
+		return
+	}
 	tflog.Trace(ctx, fmt.Sprintf("LXC %d updated", id))
 
+	if restart {
+		// start it before checking for pending changes, any status change in the plan is handled further down
+		restart = false
+		resp.Diagnostics.Append(startLXCAfterUpdate(ctx, r.client, vmr)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	reboot, err := pveapi.GuestHasPendingChanges(vmr, r.client)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -535,36 +1130,38 @@ func (r *lxcResource) Update(ctx context.Context, req resource.UpdateRequest, re
 		return
 	}
 	if reboot {
-		// RebootVm (ie POST ../status/reboot) hangs and never completes, probably because we're testing on VMs with nothing installed
+		skip, diags := skipRepeatedReboot(ctx, r.client, vmr, vmTypeLxc, "LXC", resp.Private)
+		resp.Diagnostics.Append(diags...)
+		reboot = !skip
+	} else {
+		resp.Diagnostics.Append(rememberPendingAfterReboot(ctx, resp.Private, nil)...)
+	}
+	if reboot {
 		tflog.Trace(ctx, fmt.Sprintf("Rebooting LXC %d...", id))
 
-		_, err = r.client.StopVm(vmr)
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error Updating LXC",
-				"Could not stop LXC as part of reboot after updating it, unexpected error: "+err.Error(),
-			)
-			return
-		}
-
-		_, err = r.client.StartVm(vmr)
+		err = rebootGuest(ctx, r.client, vmr, vmTypeLxc)
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Error Updating LXC",
-				"Could not start LXC as part of reboot after updating it, unexpected error: "+err.Error(),
+				"Could not reboot LXC after updating it, unexpected error: "+err.Error(),
 			)
 			return
 		}
 
 		tflog.Trace(ctx, fmt.Sprintf("Rebooted LXC %d.", id))
+
+		resp.Diagnostics.Append(checkPendingAfterReboot(ctx, r.client, vmr, vmTypeLxc, "LXC", resp.Private)...)
 	}
 
 	var newState lxcResourceModel
 
-	// carry over values not part of PVE state
-	newState.Ostemplate = state.Ostemplate
-	newState.Password = state.Password
-	newState.SSHPublicKeys = state.SSHPublicKeys
+	// carry over values not part of PVE state, from the plan since an imported LXC doesn't have them in state
+	newState.Ostemplate = plan.Ostemplate
+	newState.Password = plan.Password
+	newState.SSHPublicKeys = plan.SSHPublicKeys
+	newState.IgnoreNodeDrift = plan.IgnoreNodeDrift
+	// features all turned off read back as null unless they're in config
+	newState.Features = plan.Features
 
 	err = UpdateLXCResourceModelFromAPI(ctx, id, r.client, &newState, LXCStateEverything)
 	if err != nil {
@@ -575,7 +1172,8 @@ func (r *lxcResource) Update(ctx context.Context, req resource.UpdateRequest, re
 		return
 	}
 
-	if plan.Status.ValueString() != newState.Status.ValueString() {
+	// if the status couldn't be read there's no telling what to do, leave it for the next run
+	if plan.Status.ValueString() != newState.Status.ValueString() && !newState.Status.IsNull() {
 		switch plan.Status.ValueString() {
 		case stateRunning:
 			tflog.Trace(ctx, "Starting LXC since status in plan set to "+plan.Status.ValueString())
@@ -600,6 +1198,8 @@ func (r *lxcResource) Update(ctx context.Context, req resource.UpdateRequest, re
 		}
 	}
 
+	// the status is what it was just changed to, unless PVE tells otherwise
+	newState.Status = plan.Status
 	err = UpdateLXCResourceModelFromAPI(ctx, id, r.client, &newState, LXCStateStatus)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -624,6 +1224,9 @@ func (r *lxcResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 	if diags.HasError() {
 		return
 	}
+	defer func() {
+		resp.Diagnostics = withGuestContext(resp.Diagnostics, "LXC", state.Node.ValueString(), state.VMID.ValueInt64())
+	}()
 
 	const deleteErrorSummary string = "Error Deleting LXC"
 	tflog.Trace(ctx, fmt.Sprintf("Deleting LXC %d", state.VMID.ValueInt64()))
@@ -639,7 +1242,7 @@ func (r *lxcResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 
 	vmExists := false
 	for _, vm := range vms {
-		if int64(vm.Id) == state.VMID.ValueInt64() {
+		if int64(vm.Id) == state.VMID.ValueInt64() && vm.Type == pveapi.GuestLXC {
 			vmExists = true
 			break
 		}
@@ -654,20 +1257,16 @@ func (r *lxcResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 	vmr.SetNode(state.Node.ValueString())
 	vmr.SetVmType(vmTypeLxc)
 
-	vmState, err := r.client.GetVmState(vmr)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			deleteErrorSummary,
-			"Could not get VM state before deleting, unexpected error: "+err.Error(),
-		)
+	resp.Diagnostics.Append(checkDeletable(ctx, r.client, vmr, deleteErrorSummary)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	var ok bool
-	status, ok := vmState["status"].(string)
-	if !ok {
+
+	status, err := guestStatus(ctx, r.client, vmr)
+	if err != nil {
 		resp.Diagnostics.AddError(
 			deleteErrorSummary,
-			fmt.Sprintf("status field in VM state was not a string but %T", vmState["status"]),
+			"Could not get VM state before deleting, unexpected error: "+err.Error(),
 		)
 		return
 	}
@@ -694,11 +1293,8 @@ func (r *lxcResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 	tflog.Trace(ctx, fmt.Sprintf("LXC %d deleted", vmr.VmId()))
 }
 
-func (*lxcResource) ImportState(_ context.Context, _ resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resp.Diagnostics.AddError(
-		"ImportState Not Yet Supported",
-		"Importing existing LXC state is not currently supported, PRs welcome. :-)",
-	)
+func (r *lxcResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importGuest(ctx, r.client, vmTypeLxc, req, resp)
 }
 
 func UpdateLXCResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi.Client, model *lxcResourceModel, sm LXCStateMask) error {
@@ -719,15 +1315,10 @@ func UpdateLXCResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi
 
 	var status string
 	if sm&LXCStateStatus != 0 {
-		state, err := client.GetVmState(vmr)
+		status, err = guestStatus(ctx, client, vmr)
 		if err != nil {
 			return err
 		}
-		var ok bool
-		status, ok = state["status"].(string)
-		if !ok {
-			return fmt.Errorf("status field in VM state was not a string but %T", state["status"])
-		}
 		tflog.Trace(ctx, ".. updated status: "+status)
 	}
 
@@ -737,6 +1328,41 @@ func UpdateLXCResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi
 		model.Ostype = types.StringValue(config.OsType)
 		model.Hostname = types.StringValue(config.Hostname)
 		model.Unprivileged = types.BoolValue(config.Unprivileged)
+		// features left out stay null unless some are enabled, all turned off in config reads back as such
+		if len(config.Features) == 0 && model.Features.IsNull() {
+			model.Features = types.ObjectNull(lxcFeaturesModel{}.AttributeTypes())
+		} else {
+			dm := lxcFeaturesModel{}
+			dm.readFromAPIConfig(config.Features)
+			m, diags := types.ObjectValueFrom(ctx, dm.AttributeTypes(), dm)
+			if diags.HasError() {
+				return errors.New("Unexpected error when reading features from config")
+			}
+			model.Features = m
+		}
+		model.Onboot = types.BoolValue(config.OnBoot)
+		if config.Startup == "" {
+			model.Startup = types.StringNull()
+		} else {
+			model.Startup = types.StringValue(config.Startup)
+		}
+		// looking up the config made the API client look up the container, including its pool
+		model.Pool = poolValue(vmr)
+		model.Tags, err = tagsValue(ctx, config.Tags)
+		if err != nil {
+			return err
+		}
+		model.Description = descriptionValue(model.Description, config.Description)
+
+		if config.Cores == 0 {
+			model.Cores = types.Int64Null()
+		} else {
+			model.Cores = types.Int64Value(int64(config.Cores))
+		}
+		model.CPULimit = types.Int64Value(int64(config.CPULimit))
+		model.CPUUnits = types.Int64Value(int64(config.CPUUnits))
+		model.Memory = types.Int64Value(int64(config.Memory))
+		model.Swap = types.Int64Value(int64(config.Swap))
 
 		if len(config.RootFs) == 0 {
 			dm := rootfsModel{}
@@ -752,6 +1378,21 @@ func UpdateLXCResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi
 			model.RootFs = m
 		}
 
+		for i, mp := range model.mountpoints() {
+			dm := mountpointModel{}
+			c, ok := config.Mountpoints[i]
+			if !ok {
+				*mp = types.ObjectNull(dm.AttributeTypes())
+				continue
+			}
+			dm.readFromAPIConfig(&c)
+			m, diags := types.ObjectValueFrom(ctx, dm.AttributeTypes(), dm)
+			if diags.HasError() {
+				return fmt.Errorf("Unexpected error when reading mp%d from config", i)
+			}
+			*mp = m
+		}
+
 		if len(config.Networks) == 0 {
 			dm := lxcNetModel{}
 			dmAttrs := dm.AttributeTypes()
@@ -769,7 +1410,7 @@ func UpdateLXCResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi
 	}
 
 	if sm&LXCStateStatus != 0 {
-		model.Status = types.StringValue(status)
+		model.Status = statusValue(status, model.Status)
 	}
 
 	tflog.Trace(ctx, fmt.Sprintf("Updated lxcResourceModel from PVE API, model is now %+v", model), map[string]any{"vmid": vmid})
@@ -777,10 +1418,32 @@ func UpdateLXCResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi
 	return nil
 }
 
+// lxcZeroValueParams returns the options explicitly set to 0 (or false) in the model. The API client
+// leaves out zero values when sending a config, so these need to be set separately.
+func lxcZeroValueParams(model *lxcResourceModel) map[string]any {
+	params := map[string]any{}
+	if !model.Onboot.IsNull() && !model.Onboot.IsUnknown() && !model.Onboot.ValueBool() {
+		params["onboot"] = 0
+	}
+	if !model.CPULimit.IsNull() && !model.CPULimit.IsUnknown() && model.CPULimit.ValueInt64() == 0 {
+		params["cpulimit"] = 0
+	}
+	if !model.Swap.IsNull() && !model.Swap.IsUnknown() && model.Swap.ValueInt64() == 0 {
+		params["swap"] = 0
+	}
+	return params
+}
+
 func apiConfigFromLXCResourceModel(ctx context.Context, model *lxcResourceModel, config *pveapi.ConfigLxc) error {
 	// Node set via VmRef
 	// VMID set via VmRef
 	config.Ostemplate = model.Ostemplate.ValueString()
+	// only sent when creating, see moveGuestToPool for updates
+	config.Pool = model.Pool.ValueString()
+
+	if !model.Description.IsNull() && !model.Description.IsUnknown() {
+		config.Description = model.Description.ValueString()
+	}
 
 	if !model.Hostname.IsNull() && !model.Hostname.IsUnknown() {
 		config.Hostname = model.Hostname.ValueString()
@@ -798,7 +1461,48 @@ func apiConfigFromLXCResourceModel(ctx context.Context, model *lxcResourceModel,
 		config.Unprivileged = model.Unprivileged.ValueBool()
 	}
 
+	if !model.Onboot.IsNull() && !model.Onboot.IsUnknown() {
+		config.OnBoot = model.Onboot.ValueBool()
+	}
+
+	if !model.Startup.IsNull() && !model.Startup.IsUnknown() {
+		config.Startup = model.Startup.ValueString()
+	}
+
+	if !model.Tags.IsNull() && !model.Tags.IsUnknown() {
+		tags, err := joinTags(ctx, model.Tags)
+		if err != nil {
+			return err
+		}
+		config.Tags = tags
+	}
+
+	if !model.Cores.IsNull() && !model.Cores.IsUnknown() {
+		config.Cores = int(model.Cores.ValueInt64())
+	}
+
+	if !model.CPULimit.IsNull() && !model.CPULimit.IsUnknown() {
+		config.CPULimit = int(model.CPULimit.ValueInt64())
+	}
+
+	if !model.CPUUnits.IsNull() && !model.CPUUnits.IsUnknown() {
+		config.CPUUnits = int(model.CPUUnits.ValueInt64())
+	}
+
+	if !model.Memory.IsNull() && !model.Memory.IsUnknown() {
+		config.Memory = int(model.Memory.ValueInt64())
+	}
+
+	if !model.Swap.IsNull() && !model.Swap.IsUnknown() {
+		config.Swap = int(model.Swap.ValueInt64())
+	}
+
 	var err error
+	config.Features, err = lxcFeaturesAPIConfigFromStateValue(ctx, model.Features)
+	if err != nil {
+		return err
+	}
+
 	if !model.RootFs.IsNull() && !model.RootFs.IsUnknown() {
 		config.RootFs, err = rootfsAPIConfigFromStateValue(ctx, model.RootFs)
 		if err != nil {
@@ -806,6 +1510,11 @@ func apiConfigFromLXCResourceModel(ctx context.Context, model *lxcResourceModel,
 		}
 	}
 
+	config.Mountpoints, err = mountpointsAPIConfigFromModel(ctx, model)
+	if err != nil {
+		return err
+	}
+
 	if !model.Net.IsNull() && !model.Net.IsUnknown() {
 		net0, err := lxcNetAPIConfigFromStateValue(ctx, model.Net)
 		if err != nil {
@@ -817,6 +1526,60 @@ func apiConfigFromLXCResourceModel(ctx context.Context, model *lxcResourceModel,
 	return nil
 }
 
+// startLXCAfterUpdate starts a container that was stopped to apply changes to it.
+func startLXCAfterUpdate(ctx context.Context, client *pveapi.Client, vmr *pveapi.VmRef) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	tflog.Trace(ctx, fmt.Sprintf("Starting LXC %d again after updating it", vmr.VmId()))
+	_, err := client.StartVm(vmr)
+	if err != nil {
+		diags.AddError(
+			"Error Updating LXC",
+			"Could not start LXC again after updating it, unexpected error: "+err.Error(),
+		)
+	}
+	return diags
+}
+
+// lxcChangesRequireStop tells if going from state to plan involves changes PVE only allows on a
+// stopped container, e.g. moving the rootfs volume to another storage. Mount points can be added and
+// resized on a running container, but not moved, removed or changed otherwise.
+func lxcChangesRequireStop(ctx context.Context, state *lxcResourceModel, plan *lxcResourceModel) (bool, error) {
+	oldMps, err := mountpointsAPIConfigFromModel(ctx, state)
+	if err != nil {
+		return false, err
+	}
+	newMps, err := mountpointsAPIConfigFromModel(ctx, plan)
+	if err != nil {
+		return false, err
+	}
+	for slot, prev := range oldMps {
+		next, ok := newMps[slot]
+		if !ok {
+			return true, nil
+		}
+		for _, k := range []string{"volume", "storage", "mp", "ro", "backup"} {
+			if prev[k] != next[k] {
+				return true, nil
+			}
+		}
+	}
+
+	if state.RootFs.IsNull() || state.RootFs.IsUnknown() || plan.RootFs.IsNull() || plan.RootFs.IsUnknown() {
+		return false, nil
+	}
+
+	var prev, next rootfsModel
+	if diags := state.RootFs.As(ctx, &prev, basetypes.ObjectAsOptions{}); diags.HasError() {
+		return false, errors.New("unable to read rootfs from state")
+	}
+	if diags := plan.RootFs.As(ctx, &next, basetypes.ObjectAsOptions{}); diags.HasError() {
+		return false, errors.New("unable to read rootfs from plan")
+	}
+
+	return prev.Storage.ValueString() != next.Storage.ValueString(), nil
+}
+
 func rootfsAPIConfigFromStateValue(ctx context.Context, o basetypes.ObjectValue) (pveapi.QemuDevice, error) {
 	if o.IsNull() {
 		return nil, nil
@@ -832,6 +1595,51 @@ func rootfsAPIConfigFromStateValue(ctx context.Context, o basetypes.ObjectValue)
 	return c, nil
 }
 
+func (m *lxcResourceModel) mountpoints() []*types.Object {
+	return []*types.Object{
+		&m.Mp0, &m.Mp1, &m.Mp2, &m.Mp3, &m.Mp4, &m.Mp5, &m.Mp6, &m.Mp7, &m.Mp8, &m.Mp9,
+	}
+}
+
+// mountpointsAPIConfigFromModel returns the mount points in model keyed by slot, as the API client
+// and applyLxcDiskChanges take them.
+func mountpointsAPIConfigFromModel(ctx context.Context, model *lxcResourceModel) (pveapi.QemuDevices, error) {
+	mps := pveapi.QemuDevices{}
+	for i, o := range model.mountpoints() {
+		if o.IsNull() || o.IsUnknown() {
+			continue
+		}
+
+		var dm mountpointModel
+		if diags := o.As(ctx, &dm, basetypes.ObjectAsOptions{}); diags.HasError() {
+			return nil, fmt.Errorf("unable to create config object from mp%d state value", i)
+		}
+		c := pveapi.QemuDevice{}
+		dm.writeToAPIConfig(&c, i)
+		mps[i] = c
+	}
+	return mps, nil
+}
+
+// lxcFeaturesAPIConfigFromStateValue returns the features in o for the API client, nil if none are enabled.
+func lxcFeaturesAPIConfigFromStateValue(ctx context.Context, o basetypes.ObjectValue) (pveapi.QemuDevice, error) {
+	if o.IsNull() || o.IsUnknown() {
+		return nil, nil
+	}
+
+	var dm lxcFeaturesModel
+	diags := o.As(ctx, &dm, basetypes.ObjectAsOptions{})
+	if diags.HasError() {
+		return nil, errors.New("unable to create config object from features state value")
+	}
+	if !dm.anyEnabled() {
+		return nil, nil
+	}
+	c := pveapi.QemuDevice{}
+	dm.writeToAPIConfig(&c)
+	return c, nil
+}
+
 func lxcNetAPIConfigFromStateValue(ctx context.Context, o basetypes.ObjectValue) (pveapi.QemuDevice, error) {
 	if o.IsNull() {
 		return nil, nil
@@ -850,14 +1658,19 @@ func lxcNetAPIConfigFromStateValue(ctx context.Context, o basetypes.ObjectValue)
 func applyLxcDiskChanges(prevDisks, newDisks pveapi.QemuDevices, vmr *pveapi.VmRef, c *pveapi.Client) error {
 	// 1. Delete slots that either a. Don't exist in the new set or b. Have a different volume in the new set
 	deleteDisks := []pveapi.QemuDevice{}
+	// slots whose volume is replaced, they're added anew below rather than moved or resized
+	replaced := map[int]bool{}
 	for key, prevDisk := range prevDisks {
 		newDisk, ok := (newDisks)[key]
 		// The Rootfs can't be deleted
 		if ok && diskSlotName(newDisk) == "rootfs" {
 			continue
 		}
-		if !ok || (newDisk["volume"] != "" && prevDisk["volume"] != newDisk["volume"]) || (prevDisk["slot"] != newDisk["slot"]) {
+		// a bind mount is replaced by any other volume, including one about to be allocated
+		volumeChanged := prevDisk["volume"] != newDisk["volume"] && (newDisk["volume"] != "" || isBindMountVolume(prevDisk["volume"]))
+		if !ok || volumeChanged || (prevDisk["slot"] != newDisk["slot"]) {
 			deleteDisks = append(deleteDisks, prevDisk)
+			replaced[key] = ok && volumeChanged
 		}
 	}
 	if len(deleteDisks) > 0 {
@@ -893,8 +1706,8 @@ func applyLxcDiskChanges(prevDisks, newDisks pveapi.QemuDevices, vmr *pveapi.VmR
 			}
 		}
 
-		if !ok || newDisk["slot"] != prevDisk["slot"] {
-			newParams[diskName] = pveapi.FormatDiskParam(newDisk)
+		if !ok || newDisk["slot"] != prevDisk["slot"] || replaced[key] {
+			newParams[diskName] = formatMountpointParam(newDisk)
 		}
 	}
 	if len(newParams) > 0 {
@@ -913,7 +1726,7 @@ func applyLxcDiskChanges(prevDisks, newDisks pveapi.QemuDevices, vmr *pveapi.VmR
 	for key, prevDisk := range prevDisks {
 		newDisk, ok := newDisks[key]
 		diskName := diskSlotName(newDisk)
-		if ok {
+		if ok && !replaced[key] {
 			// 2. Move disks with mismatching storage
 			newStorage, ok := newDisk["storage"].(string)
 			if ok && newStorage != prevDisk["storage"] {
@@ -955,6 +1768,16 @@ func applyLxcDiskChanges(prevDisks, newDisks pveapi.QemuDevices, vmr *pveapi.VmR
 	return nil
 }
 
+// formatMountpointParam formats disk for the API like pveapi.FormatDiskParam does, which only ever
+// sends backup=0, adding backup=1 since PVE doesn't back up mount points without it.
+func formatMountpointParam(disk pveapi.QemuDevice) string {
+	param := pveapi.FormatDiskParam(disk)
+	if backup, ok := disk["backup"].(bool); ok && backup {
+		param += ",backup=1"
+	}
+	return param
+}
+
 func diskSlotName(disk pveapi.QemuDevice) string {
 	diskType, ok := disk["type"].(string)
 	if !ok || diskType == "" {
@@ -16,7 +16,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
 	"github.com/mollstam/terraform-provider-proxmox/proxmox/provider/testutil"
 	"github.com/onsi/gomega"
@@ -44,6 +46,12 @@ resource "proxmox_lxc" "test" {
 		size    = "1G"
 	}
 
+	mp0 = {
+		storage = "local-lvm"
+		size    = "1G"
+		mp      = "/data"
+	}
+
 	net = {
 		name   = "eth0"
 		bridge = "vmbr0"
@@ -56,11 +64,16 @@ resource "proxmox_lxc" "test" {
 					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
 					testCheckLXCValuesInPve(&lxc, types.StringValue("pve"), types.Int64Value(100), types.StringValue("alpine"), types.StringValue("wall-e"), types.BoolValue(false)),
 					testCheckLXCRootfsValuesInPve(ctx, &lxc, types.StringValue("local-lvm"), types.StringValue("1G")),
+					testCheckLXCMountpointValuesInPve(ctx, &lxc, 0, types.StringValue("local-lvm"), types.StringValue("1G"), types.StringValue("/data")),
 					testCheckLXCNetValuesInPve(ctx, &lxc, types.StringValue("eth0"), types.StringValue("vmbr0"), types.StringValue("192.168.0.50/24"), types.StringValue("192.168.0.1")),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "node", "pve"),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "hostname", "wall-e"),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "rootfs.storage", "local-lvm"),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "rootfs.size", "1G"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "mp0.storage", "local-lvm"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "mp0.size", "1G"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "mp0.mp", "/data"),
+					resource.TestCheckResourceAttrSet("proxmox_lxc.test", "mp0.volume"),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "net.name", "eth0"),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "net.bridge", "vmbr0"),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "net.ip", "192.168.0.50/24"),
@@ -80,6 +93,12 @@ resource "proxmox_lxc" "test" {
 		size    = "2G"
 	}
 
+	mp0 = {
+		storage = "local-lvm"
+		size    = "2G"
+		mp      = "/data"
+	}
+
 	net = {
 		name   = "eth0"
 		bridge = "vmbr0"
@@ -91,11 +110,13 @@ resource "proxmox_lxc" "test" {
 					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
 					testCheckLXCValuesInPve(&lxc, types.StringValue("pve"), types.Int64Value(100), types.StringValue("alpine"), types.StringValue("m-o"), types.BoolValue(false)),
 					testCheckLXCRootfsValuesInPve(ctx, &lxc, types.StringValue("local-lvm"), types.StringValue("2G")),
+					testCheckLXCMountpointValuesInPve(ctx, &lxc, 0, types.StringValue("local-lvm"), types.StringValue("2G"), types.StringValue("/data")),
 					testCheckLXCNetValuesInPve(ctx, &lxc, types.StringValue("eth0"), types.StringValue("vmbr0"), types.StringValue("dhcp"), types.StringNull()),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "node", "pve"),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "hostname", "m-o"),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "rootfs.storage", "local-lvm"),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "rootfs.size", "2G"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "mp0.size", "2G"),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "net.name", "eth0"),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "net.bridge", "vmbr0"),
 					resource.TestCheckResourceAttr("proxmox_lxc.test", "net.ip", "dhcp"),
@@ -105,6 +126,329 @@ resource "proxmox_lxc" "test" {
 	})
 }
 
+func TestAccLXCResource_AddAndRemoveMountpoints(t *testing.T) {
+	var lxc lxcResourceModel
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	rootfs = {
+		storage = "local-lvm"
+		size    = "1G"
+	}
+
+	mp0 = {
+		storage = "local-lvm"
+		size    = "1G"
+		mp      = "/data"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCMountpointValuesInPve(ctx, &lxc, 0, types.StringValue("local-lvm"), types.StringValue("1G"), types.StringValue("/data")),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "mp0.readonly", "false"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "mp0.backup", "true"),
+					resource.TestCheckNoResourceAttr("proxmox_lxc.test", "mp1"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	rootfs = {
+		storage = "local-lvm"
+		size    = "1G"
+	}
+
+	mp0 = {
+		storage = "local-lvm"
+		size    = "1G"
+		mp      = "/data"
+	}
+
+	mp1 = {
+		storage  = "local-lvm"
+		size     = "1G"
+		mp       = "/logs"
+		readonly = true
+		backup   = false
+	}
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_lxc.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCMountpointValuesInPve(ctx, &lxc, 0, types.StringValue("local-lvm"), types.StringValue("1G"), types.StringValue("/data")),
+					testCheckLXCMountpointValuesInPve(ctx, &lxc, 1, types.StringValue("local-lvm"), types.StringValue("1G"), types.StringValue("/logs")),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "mp1.readonly", "true"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "mp1.backup", "false"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	rootfs = {
+		storage = "local-lvm"
+		size    = "1G"
+	}
+
+	mp1 = {
+		storage  = "local-lvm"
+		size     = "1G"
+		mp       = "/logs"
+		readonly = true
+		backup   = false
+	}
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_lxc.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCMountpointValuesInPve(ctx, &lxc, 1, types.StringValue("local-lvm"), types.StringValue("1G"), types.StringValue("/logs")),
+					resource.TestCheckNoResourceAttr("proxmox_lxc.test", "mp0"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	rootfs = {
+		storage = "local-lvm"
+		size    = "1G"
+	}
+
+	mp1 = {
+		storage  = "local"
+		size     = "1G"
+		mp       = "/logs"
+		readonly = true
+		backup   = false
+	}
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_lxc.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectUnknownValue("proxmox_lxc.test", tfjsonpath.New("mp1").AtMapKey("volume")),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCMountpointValuesInPve(ctx, &lxc, 1, types.StringValue("local"), types.StringValue("1G"), types.StringValue("/logs")),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "mp1.mp", "/logs"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_CreateWithBindMount(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	rootfs = {
+		storage = "local-lvm"
+		size    = "1G"
+	}
+
+	mp0 = {
+		volume = "/tmp"
+		mp     = "/mnt/host-tmp"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCMountpointValuesInPve(ctx, &lxc, 0, types.StringNull(), types.StringNull(), types.StringValue("/mnt/host-tmp")),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "mp0.volume", "/tmp"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "mp0.mp", "/mnt/host-tmp"),
+					resource.TestCheckNoResourceAttr("proxmox_lxc.test", "mp0.storage"),
+					resource.TestCheckNoResourceAttr("proxmox_lxc.test", "mp0.size"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	rootfs = {
+		storage = "local-lvm"
+		size    = "1G"
+	}
+
+	mp0 = {
+		storage = "local-lvm"
+		size    = "1G"
+		mp      = "/mnt/host-tmp"
+	}
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_lxc.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCMountpointValuesInPve(ctx, &lxc, 0, types.StringValue("local-lvm"), types.StringValue("1G"), types.StringValue("/mnt/host-tmp")),
+					resource.TestMatchResourceAttr("proxmox_lxc.test", "mp0.volume", regexp.MustCompile(`^local-lvm:`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_BindMountWithStorage_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	rootfs = {
+		storage = "local-lvm"
+		size    = "1G"
+	}
+
+	mp0 = {
+		volume  = "/tmp"
+		storage = "local-lvm"
+		size    = "1G"
+		mp      = "/mnt/host-tmp"
+	}
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_CreateAndUpdateFeatures(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node         = "pve"
+	ostemplate   = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+	unprivileged = true
+
+	features = {
+		nesting = true
+	}
+
+	rootfs = {
+		storage = "local-lvm"
+		size    = "1G"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCConfigValueInPve(&lxc, "features", "nesting=1"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "features.nesting", "true"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "features.keyctl", "false"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "features.fuse", "false"),
+					resource.TestCheckNoResourceAttr("proxmox_lxc.test", "features.mount"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node         = "pve"
+	ostemplate   = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+	unprivileged = true
+
+	features = {
+		nesting = true
+		keyctl  = true
+		fuse    = true
+	}
+
+	rootfs = {
+		storage = "local-lvm"
+		size    = "1G"
+	}
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_lxc.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "features.nesting", "true"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "features.keyctl", "true"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "features.fuse", "true"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node         = "pve"
+	ostemplate   = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+	unprivileged = true
+
+	rootfs = {
+		storage = "local-lvm"
+		size    = "1G"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCConfigValueInPve(&lxc, "features", nil),
+					resource.TestCheckNoResourceAttr("proxmox_lxc.test", "features"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccLXCResource_CreateAndUpdatePassword(t *testing.T) {
 	var lxc lxcResourceModel
 
@@ -257,6 +601,46 @@ resource "proxmox_lxc" "test" {
 	})
 }
 
+func TestAccLXCResource_UpdateRequiringReboot_IsRebooted(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCStatusInPve(&lxc, stateRunning),
+				),
+			},
+			{
+				// ostype can't be changed on a running container, it's left pending until rebooted
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+	ostype     = "unmanaged"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCStatusInPve(&lxc, stateRunning),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "ostype", "unmanaged"),
+					testCheckLXCRebootedInPve(&lxc),
+				),
+			},
+		},
+	})
+}
+
 func TestAccLXCResource_CreateTwoLXCs_GetSequentialIds(t *testing.T) {
 	var lxca, lxcb lxcResourceModel
 
@@ -360,7 +744,7 @@ resource "proxmox_lxc" "test" {
 	})
 }
 
-func TestAccLXCResource_ApplyOutOfBandModified_IsReconciledToPlan(t *testing.T) {
+func TestAccLXCResource_MoveRootfsOfRunningLXC_IsStoppedAndStartedAgain(t *testing.T) {
 	var lxc lxcResourceModel
 
 	ctx := testutil.GetTestLoggingContext()
@@ -371,35 +755,752 @@ func TestAccLXCResource_ApplyOutOfBandModified_IsReconciledToPlan(t *testing.T)
 			{
 				Config: providerConfig + `
 resource "proxmox_lxc" "test" {
-	node = "pve"
+	node        = "pve"
 	ostemplate  = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
 
-	hostname = "wall-e"
-}
-`,
+	rootfs = {
+		storage = "local-lvm"
+		size    = "1G"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCStatusInPve(&lxc, "running"),
+					testCheckLXCRootfsValuesInPve(ctx, &lxc, types.StringValue("local-lvm"), types.StringValue("1G")),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node        = "pve"
+	ostemplate  = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	rootfs = {
+		storage = "local"
+		size    = "1G"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCStatusInPve(&lxc, "running"),
+					testCheckLXCRootfsValuesInPve(ctx, &lxc, types.StringValue("local"), types.StringValue("1G")),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "vmid", "100"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "status", "running"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "rootfs.storage", "local"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_UpdateFailingAfterStop_IsStartedAgain(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node        = "pve"
+	ostemplate  = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	rootfs = {
+		storage = "local-lvm"
+		size    = "1G"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCStatusInPve(&lxc, "running"),
+				),
+			},
+			{
+				// moving the rootfs stops the container, then moving it to a pool that doesn't exist fails
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node        = "pve"
+	ostemplate  = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	rootfs = {
+		storage = "local"
+		size    = "1G"
+	}
+
+	pool = "nosuchpool"
+}
+`,
+				ExpectError: regexp.MustCompile(`Could not move LXC to pool`),
+			},
+			{
+				RefreshState: true,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCStatusInPve(&lxc, "running"),
+					testCheckLXCRootfsValuesInPve(ctx, &lxc, types.StringValue("local"), types.StringValue("1G")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_ApplyOutOfBandModified_IsReconciledToPlan(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node = "pve"
+	ostemplate  = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	hostname = "wall-e"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+				),
+			},
+			{
+				PreConfig: testutil.ComposeFunc(
+					setLXCHostnameInPve(&lxc, "m-o"),
+				),
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node = "pve"
+	ostemplate  = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	hostname = "wall-e"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCValuesInPve(&lxc, types.StringValue("pve"), types.Int64Value(100), types.StringValue("alpine"), types.StringValue("wall-e"), types.BoolValue(false)),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "node", "pve"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "hostname", "wall-e"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_Rename_IsUpdatedInPlace(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	hostname = "wall-e"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "hostname", "wall-e"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	hostname = "eve"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_lxc.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCValuesInPve(&lxc, types.StringValue("pve"), types.Int64Value(100), types.StringValue("alpine"), types.StringValue("eve"), types.BoolValue(false)),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "hostname", "eve"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_CreateAndUpdateResources(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	cores    = 1
+	cpuunits = 100
+	memory   = 256
+	swap     = 0
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCResourcesInPve(&lxc, types.Int64Value(1), types.Int64Value(0), types.Int64Value(100), types.Int64Value(256), types.Int64Value(0)),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "cores", "1"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "cpulimit", "0"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "cpuunits", "100"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "memory", "256"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "swap", "0"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	cores    = 2
+	cpulimit = 1
+	cpuunits = 200
+	memory   = 1024
+	swap     = 256
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_lxc.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCResourcesInPve(&lxc, types.Int64Value(2), types.Int64Value(1), types.Int64Value(200), types.Int64Value(1024), types.Int64Value(256)),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "vmid", "100"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	cores    = 2
+	cpulimit = 0
+	cpuunits = 200
+	memory   = 1024
+	swap     = 0
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					testCheckLXCResourcesInPve(&lxc, types.Int64Value(2), types.Int64Value(0), types.Int64Value(200), types.Int64Value(1024), types.Int64Value(0)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_CreateWithOnboot_RefreshHasNoDiff(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	config := providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	onboot  = true
+	startup = "order=2,up=30"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "onboot", "true"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "startup", "order=2,up=30"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_lxc.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "onboot", "false"),
+					resource.TestCheckNoResourceAttr("proxmox_lxc.test", "startup"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_MultilineDescription_RefreshHasNoDiff(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	config := providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	description = <<-EOT
+		# Wall-E
+
+		Waste Allocation Load Lifter: *Earth-Class*
+	EOT
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "description", "# Wall-E\n\nWaste Allocation Load Lifter: *Earth-Class*\n"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckNoResourceAttr("proxmox_lxc.test", "description"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_EmptyDescription_RefreshHasNoDiff(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	config := providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	description = ""
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	description = "Waste Allocation Load Lifter"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "description", "Waste Allocation Load Lifter"),
+				),
+			},
+			{
+				// PVE stores an empty description as none at all
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "description", ""),
+					testCheckLXCConfigValueInPve(&lxc, "description", nil),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_CreateWithoutNode_UsesDefaultNode(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfigWithDefaultNode + `
+resource "proxmox_lxc" "test" {
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "node", "pve"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_InvalidHostname_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	hostname = "wall_e"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_CreateWithSwapWithoutMemory_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	swap = 1024
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_DeleteProtectedLXC_CausesError(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+`,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
 				),
 			},
 			{
-				PreConfig: testutil.ComposeFunc(
-					setLXCHostnameInPve(&lxc, "m-o"),
+				PreConfig: setLXCOptionInPve(&lxc, "protection", 1),
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+`,
+				Destroy:     true,
+				ExpectError: regexp.MustCompile(`LXC \d+ has protection enabled`),
+			},
+			{
+				// allow the test to clean up after itself
+				PreConfig: setLXCOptionInPve(&lxc, "protection", 0),
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+`,
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_DeleteLockedLXC_WaitsForLock(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	config := providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+	hostname   = "m-o"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testCheckNoVMNamedInPve("m-o"),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
 				),
+			},
+			{
+				// the lock is released while the delete waits for it, as when a backup finishes
+				PreConfig: func() {
+					setLXCOptionInPve(&lxc, "lock", "backup")()
+					time.AfterFunc(10*time.Second, setLXCOptionInPve(&lxc, "delete", "lock"))
+				},
+				Config:  config,
+				Destroy: true,
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_CreateWithUnknownBridge_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
 				Config: providerConfig + `
 resource "proxmox_lxc" "test" {
-	node = "pve"
-	ostemplate  = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
 
-	hostname = "wall-e"
+	net = {
+		name   = "eth0"
+		bridge = "vmbr9"
+		ip     = "dhcp"
+	}
+}
+`,
+				ExpectError: regexp.MustCompile(`The bridge vmbr9 does not exist on node 'pve'`),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_CreateAndUpdatePool(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	for _, poolid := range []string{"tf-test-a", "tf-test-b"} {
+		cleanUpFunc, err := createPoolInPve(poolid)
+		if err != nil {
+			t.Error("Error during setup: " + err.Error())
+			return
+		}
+		defer cleanUpFunc()
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+	pool       = "tf-test-a"
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
-					testCheckLXCValuesInPve(&lxc, types.StringValue("pve"), types.Int64Value(100), types.StringValue("alpine"), types.StringValue("wall-e"), types.BoolValue(false)),
-					resource.TestCheckResourceAttr("proxmox_lxc.test", "node", "pve"),
-					resource.TestCheckResourceAttr("proxmox_lxc.test", "hostname", "wall-e"),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "pool", "tf-test-a"),
+					testCheckGuestPoolInPve(&lxc.VMID, "tf-test-a"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+	pool       = "tf-test-b"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_lxc.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "pool", "tf-test-b"),
+					testCheckGuestPoolInPve(&lxc.VMID, "tf-test-b"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckNoResourceAttr("proxmox_lxc.test", "pool"),
+					testCheckGuestPoolInPve(&lxc.VMID, ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_CreateAndUpdateTags(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	tags = ["web", "prod"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "tags.#", "2"),
+					resource.TestCheckTypeSetElemAttr("proxmox_lxc.test", "tags.*", "prod"),
+					resource.TestCheckTypeSetElemAttr("proxmox_lxc.test", "tags.*", "web"),
+				),
+			},
+			{
+				// tags written with another separator, in another order, are the same tags
+				PreConfig: setLXCOptionInPve(&lxc, "tags", "web,prod"),
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	tags = ["prod", "web"]
+}
+`,
+				PlanOnly: true,
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+
+	tags = ["prod", "web", "db"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckResourceAttr("proxmox_lxc.test", "tags.#", "3"),
+					resource.TestCheckTypeSetElemAttr("proxmox_lxc.test", "tags.*", "db"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
+					resource.TestCheckNoResourceAttr("proxmox_lxc.test", "tags.#"),
+					func(_ *terraform.State) error {
+						if !lxc.Tags.IsNull() {
+							return fmt.Errorf("expected no tags in PVE, got %s", lxc.Tags.String())
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccLXCResource_Import(t *testing.T) {
+	var lxc lxcResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	config := providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+	hostname   = "eve"
+	password   = "Hunter2!"
+	cores      = 2
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckLXCExistsInPve(ctx, "proxmox_lxc.test", &lxc),
 				),
 			},
+			{
+				Config:            config,
+				ResourceName:      "proxmox_lxc.test",
+				ImportState:       true,
+				ImportStateId:     "pve/100",
+				ImportStateVerify: true,
+				// only used when creating the container, can't be read back
+				ImportStateVerifyIgnore: []string{"ostemplate", "password", "ssh_public_keys"},
+			},
 		},
 	})
 }
@@ -442,6 +1543,71 @@ resource "proxmox_lxc" "test" {
 	})
 }
 
+func TestRootfsModel_ReadFromAPIConfig(t *testing.T) {
+	tests := []struct {
+		device  pveapi.QemuDevice
+		volume  string
+		storage string
+		size    string
+	}{
+		{pveapi.QemuDevice{"volume": "local-lvm:vm-100-disk-0", "size": "8G"}, "local-lvm:vm-100-disk-0", "local-lvm", "8G"},
+		{pveapi.QemuDevice{"volume": "local-lvm:vm-100-disk-0,size=8G"}, "local-lvm:vm-100-disk-0", "local-lvm", "8G"},
+		{pveapi.QemuDevice{"volume": "local:100/vm-100-disk-0.raw,mountoptions=noatime,size=512M"}, "local:100/vm-100-disk-0.raw", "local", "512M"},
+		{pveapi.QemuDevice{"volume": "local-lvm:3"}, "local-lvm:3", "local-lvm", "3G"},
+	}
+
+	for _, tt := range tests {
+		var m rootfsModel
+		m.readFromAPIConfig(&tt.device)
+		if m.Volume.ValueString() != tt.volume || m.Storage.ValueString() != tt.storage || m.Size.ValueString() != tt.size {
+			t.Errorf("reading %v got volume=%s storage=%s size=%s, want volume=%s storage=%s size=%s", tt.device, m.Volume, m.Storage, m.Size, tt.volume, tt.storage, tt.size)
+		}
+	}
+}
+
+func TestMountpointModel_ReadFromAPIConfig(t *testing.T) {
+	tests := []struct {
+		conf    string
+		volume  string
+		storage types.String
+		size    types.String
+		backup  bool
+	}{
+		{"local-lvm:vm-100-disk-1,mp=/data,size=1G", "local-lvm:vm-100-disk-1", types.StringValue("local-lvm"), types.StringValue("1G"), false},
+		{"local-lvm:vm-100-disk-1,backup=1,mp=/data,size=1G", "local-lvm:vm-100-disk-1", types.StringValue("local-lvm"), types.StringValue("1G"), true},
+		{"local-lvm:vm-100-disk-1,backup=0,mp=/data,size=1G", "local-lvm:vm-100-disk-1", types.StringValue("local-lvm"), types.StringValue("1G"), false},
+		{"/mnt/data,mp=/data", "/mnt/data", types.StringNull(), types.StringNull(), false},
+	}
+
+	for _, tt := range tests {
+		var m mountpointModel
+		device := pveapi.ParseLxcDisk(tt.conf)
+		m.readFromAPIConfig(&device)
+		if m.Volume.ValueString() != tt.volume || !m.Storage.Equal(tt.storage) || !m.Size.Equal(tt.size) || m.MP.ValueString() != "/data" || m.Backup.ValueBool() != tt.backup {
+			t.Errorf("reading %s got volume=%s storage=%s size=%s mp=%s backup=%s, want volume=%s storage=%s size=%s mp=/data backup=%t", tt.conf, m.Volume, m.Storage, m.Size, m.MP, m.Backup, tt.volume, tt.storage, tt.size, tt.backup)
+		}
+	}
+}
+
+func TestMountpointModel_WriteToAPIConfig(t *testing.T) {
+	tests := []struct {
+		model mountpointModel
+		param string
+	}{
+		{mountpointModel{Volume: types.StringUnknown(), Storage: types.StringValue("local-lvm"), Size: types.StringValue("1G"), MP: types.StringValue("/data"), ReadOnly: types.BoolValue(false), Backup: types.BoolValue(true)}, "local-lvm:1,mp=/data,backup=1"},
+		{mountpointModel{Volume: types.StringUnknown(), Storage: types.StringValue("local-lvm"), Size: types.StringValue("1G"), MP: types.StringValue("/data"), ReadOnly: types.BoolValue(false), Backup: types.BoolValue(false)}, "local-lvm:1,backup=0,mp=/data"},
+		{mountpointModel{Volume: types.StringValue("/mnt/data"), Storage: types.StringNull(), Size: types.StringNull(), MP: types.StringValue("/data"), ReadOnly: types.BoolValue(false), Backup: types.BoolValue(true)}, "/mnt/data,mp=/data,backup=1"},
+	}
+
+	for _, tt := range tests {
+		c := pveapi.QemuDevice{}
+		tt.model.writeToAPIConfig(&c, 0)
+		if got := formatMountpointParam(c); got != tt.param {
+			t.Errorf("writing %v got %s, want %s", tt.model, got, tt.param)
+		}
+	}
+}
+
 func setLXCHostnameInPve(r *lxcResourceModel, hostname string) func() {
 	return func() {
 		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
@@ -459,6 +1625,19 @@ func setLXCHostnameInPve(r *lxcResourceModel, hostname string) func() {
 	}
 }
 
+func setLXCOptionInPve(r *lxcResourceModel, key string, value any) func() {
+	return func() {
+		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
+		ref.SetNode(r.Node.ValueString())
+		ref.SetVmType("lxc")
+
+		_, err := testutil.TestClient.SetLxcConfig(ref, map[string]any{key: value})
+		if err != nil {
+			panic(fmt.Sprintf("Unexpected error when test setting LXC option %s, updating config in API resulted in error: %s", key, err.Error()))
+		}
+	}
+}
+
 func testCheckLXCExistsInPve(ctx context.Context, n string, r *lxcResourceModel) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -480,6 +1659,32 @@ func testCheckLXCExistsInPve(ctx context.Context, n string, r *lxcResourceModel)
 	}
 }
 
+func testCheckLXCConfigValueInPve(r *lxcResourceModel, key string, value any) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
+		ref.SetNode(r.Node.ValueString())
+		ref.SetVmType("lxc")
+
+		config, err := testutil.TestClient.GetVmConfig(ref)
+		if err != nil {
+			return err
+		}
+
+		err = gomega.InterceptGomegaFailure(func() {
+			if value == nil {
+				gomega.Expect(config).NotTo(gomega.HaveKey(key))
+			} else {
+				gomega.Expect(config[key]).To(gomega.Equal(value))
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
 func testCheckLXCStatusInPve(r *lxcResourceModel, status string) resource.TestCheckFunc {
 	return func(_ *terraform.State) error {
 		err := gomega.InterceptGomegaFailure(func() {
@@ -495,6 +1700,33 @@ func testCheckLXCStatusInPve(r *lxcResourceModel, status string) resource.TestCh
 	}
 }
 
+// testCheckLXCRebootedInPve checks that the container has been rebooted through the reboot API, and
+// never stopped.
+func testCheckLXCRebootedInPve(r *lxcResourceModel) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		tasks, err := testutil.TestClient.GetItemListInterfaceArray(fmt.Sprintf("/nodes/%s/tasks?vmid=%d", r.Node.ValueString(), r.VMID.ValueInt64()))
+		if err != nil {
+			return err
+		}
+
+		statuses := map[string]string{}
+		for _, t := range tasks {
+			task := t.(map[string]any)
+			statuses[task["type"].(string)], _ = task["status"].(string)
+		}
+
+		err = gomega.InterceptGomegaFailure(func() {
+			gomega.Expect(statuses).To(gomega.HaveKeyWithValue("vzreboot", "OK"), "LXC should have been rebooted")
+			gomega.Expect(statuses).ToNot(gomega.HaveKey("vzstop"), "LXC should not have been stopped")
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
 func testCheckLXCValuesInPve(r *lxcResourceModel, node basetypes.StringValue, vmid basetypes.Int64Value, ostype basetypes.StringValue, hostname basetypes.StringValue, unprivileged basetypes.BoolValue) resource.TestCheckFunc {
 	return func(_ *terraform.State) error {
 		err := gomega.InterceptGomegaFailure(func() {
@@ -512,6 +1744,23 @@ func testCheckLXCValuesInPve(r *lxcResourceModel, node basetypes.StringValue, vm
 	}
 }
 
+func testCheckLXCResourcesInPve(r *lxcResourceModel, cores basetypes.Int64Value, cpulimit basetypes.Int64Value, cpuunits basetypes.Int64Value, memory basetypes.Int64Value, swap basetypes.Int64Value) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		err := gomega.InterceptGomegaFailure(func() {
+			gomega.Expect(r.Cores).To(gomega.Equal(cores))
+			gomega.Expect(r.CPULimit).To(gomega.Equal(cpulimit))
+			gomega.Expect(r.CPUUnits).To(gomega.Equal(cpuunits))
+			gomega.Expect(r.Memory).To(gomega.Equal(memory))
+			gomega.Expect(r.Swap).To(gomega.Equal(swap))
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
 func testCheckLXCRootfsValuesInPve(ctx context.Context, r *lxcResourceModel, storage basetypes.StringValue, size basetypes.StringValue) resource.TestCheckFunc {
 	return func(_ *terraform.State) error {
 		err := gomega.InterceptGomegaFailure(func() {
@@ -533,6 +1782,29 @@ func testCheckLXCRootfsValuesInPve(ctx context.Context, r *lxcResourceModel, sto
 	}
 }
 
+func testCheckLXCMountpointValuesInPve(ctx context.Context, r *lxcResourceModel, slot int, storage basetypes.StringValue, size basetypes.StringValue, mp basetypes.StringValue) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		err := gomega.InterceptGomegaFailure(func() {
+			mpObj := r.mountpoints()[slot]
+			gomega.Expect(mpObj.IsNull()).To(gomega.BeFalseBecause("mp%d should not be null", slot))
+
+			var mm mountpointModel
+			diags := mpObj.As(ctx, &mm, basetypes.ObjectAsOptions{})
+			if diags.HasError() {
+				panic("error when reading mount point from resource model")
+			}
+			gomega.Expect(mm.Storage).To(gomega.Equal(storage))
+			gomega.Expect(mm.Size).To(gomega.Equal(size))
+			gomega.Expect(mm.MP).To(gomega.Equal(mp))
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
 func testCheckLXCNetValuesInPve(ctx context.Context, r *lxcResourceModel, name basetypes.StringValue, bridge basetypes.StringValue, ip basetypes.StringValue, gw basetypes.StringValue) resource.TestCheckFunc {
 	return func(_ *terraform.State) error {
 		err := gomega.InterceptGomegaFailure(func() {
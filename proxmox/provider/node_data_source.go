@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+var (
+	_ datasource.DataSource              = &nodeDataSource{}
+	_ datasource.DataSourceWithConfigure = &nodeDataSource{}
+)
+
+func NewNodeDataSource() datasource.DataSource {
+	return &nodeDataSource{}
+}
+
+type nodeDataSource struct {
+	client *pveapi.Client
+}
+
+type nodeDataSourceModel struct {
+	Node          types.String `tfsdk:"node"`
+	CPUs          types.Int64  `tfsdk:"cpus"`
+	MemoryTotal   types.Int64  `tfsdk:"memory_total"`
+	MemoryUsed    types.Int64  `tfsdk:"memory_used"`
+	Uptime        types.Int64  `tfsdk:"uptime"`
+	KernelVersion types.String `tfsdk:"kernel_version"`
+	PVEVersion    types.String `tfsdk:"pve_version"`
+}
+
+func (*nodeDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_node"
+}
+
+func (*nodeDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads the capacity and versions of a cluster node.",
+		Attributes: map[string]schema.Attribute{
+			"node": schema.StringAttribute{
+				Description: "The name of the cluster node.",
+				Required:    true,
+			},
+			"cpus": schema.Int64Attribute{
+				Description: "Number of logical CPUs of the node.",
+				Computed:    true,
+			},
+			"memory_total": schema.Int64Attribute{
+				Description: "Total memory of the node in MB.",
+				Computed:    true,
+			},
+			"memory_used": schema.Int64Attribute{
+				Description: "Memory in use on the node in MB, at the time it was read.",
+				Computed:    true,
+			},
+			"uptime": schema.Int64Attribute{
+				Description: "Seconds since the node booted.",
+				Computed:    true,
+			},
+			"kernel_version": schema.StringAttribute{
+				Description: "Release of the running kernel, e.g. \"6.5.11-8-pve\".",
+				Computed:    true,
+			},
+			"pve_version": schema.StringAttribute{
+				Description: "Version of Proxmox VE on the node, e.g. \"8.1.4\".",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *nodeDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*proxmoxProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			fmt.Sprintf("Expected %T, got: %T. Please report this to the provider developers.", data, req.ProviderData),
+		)
+		return
+	}
+
+	d.client = data.client
+}
+
+func (d *nodeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config nodeDataSourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	node := config.Node.ValueString()
+	resp.Diagnostics.Append(checkNode(d.client, node, "Error Reading Node")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the status of the named node, the client's GetVersion only knows about the node the API is accessed through
+	status, err := d.client.GetItemConfigMapStringInterface("/nodes/"+node+"/status", "node", "status")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Node",
+			fmt.Sprintf("Could not read status of node '%s', unexpected error: %s", node, err.Error()),
+		)
+		return
+	}
+
+	cpuinfo, _ := status["cpuinfo"].(map[string]any)
+	cpus, _ := cpuinfo["cpus"].(float64)
+	memory, _ := status["memory"].(map[string]any)
+	total, _ := memory["total"].(float64)
+	used, _ := memory["used"].(float64)
+	uptime, _ := status["uptime"].(float64)
+
+	config.CPUs = types.Int64Value(int64(cpus))
+	config.MemoryTotal = types.Int64Value(int64(total) / (1024 * 1024))
+	config.MemoryUsed = types.Int64Value(int64(used) / (1024 * 1024))
+	config.Uptime = types.Int64Value(int64(uptime))
+	config.KernelVersion = types.StringValue(nodeKernelRelease(status))
+	config.PVEVersion = types.StringValue(nodePVEVersion(status))
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
+
+// nodeKernelRelease gets the kernel release from a node status. Newer PVE has it on its own, older
+// only as part of kversion, e.g. "Linux 6.5.11-8-pve #1 SMP PREEMPT_DYNAMIC ...".
+func nodeKernelRelease(status map[string]any) string {
+	if kernel, ok := status["current-kernel"].(map[string]any); ok {
+		if release, _ := kernel["release"].(string); release != "" {
+			return release
+		}
+	}
+
+	kversion, _ := status["kversion"].(string)
+	fields := strings.Fields(kversion)
+	if len(fields) < 2 {
+		return kversion
+	}
+	return fields[1]
+}
+
+// nodePVEVersion gets the PVE version from a node status, which has it as e.g.
+// "pve-manager/8.1.4/ec5affc9e41f1d79".
+func nodePVEVersion(status map[string]any) string {
+	pveversion, _ := status["pveversion"].(string)
+	parts := strings.Split(pveversion, "/")
+	if len(parts) < 2 {
+		return pveversion
+	}
+	return parts[1]
+}
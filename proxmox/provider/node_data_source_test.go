@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccNodeDataSource_Read(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "proxmox_node" "test" {
+	node = "pve"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.proxmox_node.test", "node", "pve"),
+					resource.TestMatchResourceAttr("data.proxmox_node.test", "cpus", regexp.MustCompile(`^[1-9][0-9]*$`)),
+					resource.TestMatchResourceAttr("data.proxmox_node.test", "memory_total", regexp.MustCompile(`^[1-9][0-9]*$`)),
+					resource.TestMatchResourceAttr("data.proxmox_node.test", "memory_used", regexp.MustCompile(`^[1-9][0-9]*$`)),
+					resource.TestMatchResourceAttr("data.proxmox_node.test", "uptime", regexp.MustCompile(`^[0-9]+$`)),
+					resource.TestMatchResourceAttr("data.proxmox_node.test", "kernel_version", regexp.MustCompile(`^\d+\.\d+\.\d+`)),
+					resource.TestMatchResourceAttr("data.proxmox_node.test", "pve_version", regexp.MustCompile(`^\d+\.\d+`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccNodeDataSource_UnknownNode_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "proxmox_node" "test" {
+	node = "nonexistent"
+}
+`,
+				ExpectError: regexp.MustCompile(`Node Not Found`),
+			},
+		},
+	})
+}
@@ -0,0 +1,352 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ planmodifier.String = ignoreNodeDriftModifier{}
+
+// ignoreNodeDriftModifier keeps the node the guest is currently on in the plan when ignore_node_drift
+// is set, so that Terraform doesn't try to move a guest back after HA has migrated it.
+type ignoreNodeDriftModifier struct{}
+
+func (m ignoreNodeDriftModifier) Description(_ context.Context) string {
+	return "Keeps the current node in state if ignore_node_drift is true."
+}
+
+func (m ignoreNodeDriftModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m ignoreNodeDriftModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.StateValue.IsNull() || req.PlanValue.IsUnknown() || req.PlanValue.Equal(req.StateValue) {
+		return
+	}
+
+	var ignore types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("ignore_node_drift"), &ignore)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if ignore.ValueBool() {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+func IgnoreNodeDrift() planmodifier.String {
+	return ignoreNodeDriftModifier{}
+}
+
+var (
+	_ planmodifier.Object = removeUnlessClonedModifier{}
+	_ planmodifier.String = removeUnlessClonedModifier{}
+	_ planmodifier.Int64  = removeUnlessClonedModifier{}
+	_ planmodifier.List   = removeUnlessClonedModifier{}
+	_ planmodifier.Set    = removeUnlessClonedModifier{}
+)
+
+// removeUnlessClonedModifier plans an Optional+Computed value removed from config as null so that it's
+// removed from the guest. Guests cloned from a template inherit the value without configuring it, so
+// for those the current value is kept instead.
+type removeUnlessClonedModifier struct{}
+
+func (m removeUnlessClonedModifier) Description(_ context.Context) string {
+	return "Removes the value if it's removed from config, unless the guest is a clone in which case the current value is kept."
+}
+
+func (m removeUnlessClonedModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m removeUnlessClonedModifier) PlanModifyObject(ctx context.Context, req planmodifier.ObjectRequest, resp *planmodifier.ObjectResponse) {
+	resp.Diagnostics.Append(planRemoveUnlessCloned(ctx, req.Config, req.ConfigValue, req.StateValue, types.ObjectNull(req.StateValue.AttributeTypes(ctx)), &resp.PlanValue)...)
+}
+
+func (m removeUnlessClonedModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	resp.Diagnostics.Append(planRemoveUnlessCloned(ctx, req.Config, req.ConfigValue, req.StateValue, types.StringNull(), &resp.PlanValue)...)
+}
+
+func (m removeUnlessClonedModifier) PlanModifyInt64(ctx context.Context, req planmodifier.Int64Request, resp *planmodifier.Int64Response) {
+	resp.Diagnostics.Append(planRemoveUnlessCloned(ctx, req.Config, req.ConfigValue, req.StateValue, types.Int64Null(), &resp.PlanValue)...)
+}
+
+func (m removeUnlessClonedModifier) PlanModifyList(ctx context.Context, req planmodifier.ListRequest, resp *planmodifier.ListResponse) {
+	resp.Diagnostics.Append(planRemoveUnlessCloned(ctx, req.Config, req.ConfigValue, req.StateValue, types.ListNull(req.StateValue.ElementType(ctx)), &resp.PlanValue)...)
+}
+
+func (m removeUnlessClonedModifier) PlanModifySet(ctx context.Context, req planmodifier.SetRequest, resp *planmodifier.SetResponse) {
+	resp.Diagnostics.Append(planRemoveUnlessCloned(ctx, req.Config, req.ConfigValue, req.StateValue, types.SetNull(req.StateValue.ElementType(ctx)), &resp.PlanValue)...)
+}
+
+// planRemoveUnlessCloned sets planValue for removeUnlessClonedModifier, whatever the type of the value.
+// A value in state that's removed from config is planned as null, or as stateValue for a clone.
+func planRemoveUnlessCloned[T attr.Value](ctx context.Context, config tfsdk.Config, configValue, stateValue, null T, planValue *T) diag.Diagnostics {
+	if stateValue.IsNull() || !configValue.IsNull() {
+		return nil
+	}
+
+	clone, diags := isClone(ctx, config)
+	if diags.HasError() {
+		return diags
+	}
+
+	if clone {
+		*planValue = stateValue
+	} else {
+		*planValue = null
+	}
+	return diags
+}
+
+func RemoveUnlessCloned() planmodifier.Object {
+	return removeUnlessClonedModifier{}
+}
+
+func RemoveStringUnlessCloned() planmodifier.String {
+	return removeUnlessClonedModifier{}
+}
+
+func RemoveInt64UnlessCloned() planmodifier.Int64 {
+	return removeUnlessClonedModifier{}
+}
+
+func RemoveListUnlessCloned() planmodifier.List {
+	return removeUnlessClonedModifier{}
+}
+
+func RemoveSetUnlessCloned() planmodifier.Set {
+	return removeUnlessClonedModifier{}
+}
+
+var (
+	_ planmodifier.String = keepClonedModifier{}
+	_ planmodifier.Int64  = keepClonedModifier{}
+	_ planmodifier.Bool   = keepClonedModifier{}
+)
+
+// keepClonedModifier is for Optional+Computed values with a default. A guest cloned from a template
+// inherits the value instead of getting the default when it's not configured, so for those it's planned
+// as unknown when creating, to be read back from the clone, and as the current value after that.
+type keepClonedModifier struct{}
+
+func (m keepClonedModifier) Description(_ context.Context) string {
+	return "Uses the value inherited from the template instead of the default if the guest is a clone and the value isn't configured."
+}
+
+func (m keepClonedModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m keepClonedModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	resp.Diagnostics.Append(planKeepCloned(ctx, req.Config, req.Plan, req.State, req.ConfigValue, req.StateValue, types.StringUnknown(), &resp.PlanValue)...)
+}
+
+func (m keepClonedModifier) PlanModifyInt64(ctx context.Context, req planmodifier.Int64Request, resp *planmodifier.Int64Response) {
+	resp.Diagnostics.Append(planKeepCloned(ctx, req.Config, req.Plan, req.State, req.ConfigValue, req.StateValue, types.Int64Unknown(), &resp.PlanValue)...)
+}
+
+func (m keepClonedModifier) PlanModifyBool(ctx context.Context, req planmodifier.BoolRequest, resp *planmodifier.BoolResponse) {
+	resp.Diagnostics.Append(planKeepCloned(ctx, req.Config, req.Plan, req.State, req.ConfigValue, req.StateValue, types.BoolUnknown(), &resp.PlanValue)...)
+}
+
+// planKeepCloned sets planValue for keepClonedModifier, whatever the type of the value. If the value
+// isn't configured for a guest that's a clone it's planned as unknown when creating and as stateValue
+// after that.
+func planKeepCloned[T attr.Value](ctx context.Context, config tfsdk.Config, plan tfsdk.Plan, state tfsdk.State, configValue, stateValue, unknown T, planValue *T) diag.Diagnostics {
+	if !configValue.IsNull() || plan.Raw.IsNull() {
+		return nil
+	}
+
+	clone, diags := isClone(ctx, config)
+	if diags.HasError() || !clone {
+		return diags
+	}
+
+	if state.Raw.IsNull() {
+		*planValue = unknown
+	} else {
+		*planValue = stateValue
+	}
+	return diags
+}
+
+// isClone tells if the guest in config is cloned from a template.
+func isClone(ctx context.Context, config tfsdk.Config) (bool, diag.Diagnostics) {
+	var clone types.String
+	diags := config.GetAttribute(ctx, path.Root("clone"), &clone)
+	return !clone.IsNull(), diags
+}
+
+func KeepStringIfCloned() planmodifier.String {
+	return keepClonedModifier{}
+}
+
+func KeepInt64IfCloned() planmodifier.Int64 {
+	return keepClonedModifier{}
+}
+
+func KeepBoolIfCloned() planmodifier.Bool {
+	return keepClonedModifier{}
+}
+
+var _ planmodifier.Map = useStateUnlessNetsChangeModifier{}
+
+// useStateUnlessNetsChangeModifier keeps a value derived from the network devices of a VM, like
+// mac_addresses, as it is in state unless any of net to net7 changes.
+type useStateUnlessNetsChangeModifier struct{}
+
+func (m useStateUnlessNetsChangeModifier) Description(_ context.Context) string {
+	return "Once set, the value of this attribute in state will not change unless the network devices change."
+}
+
+func (m useStateUnlessNetsChangeModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m useStateUnlessNetsChangeModifier) PlanModifyMap(ctx context.Context, req planmodifier.MapRequest, resp *planmodifier.MapResponse) {
+	// nothing to keep when creating or destroying, or if the value is known anyway
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || !req.PlanValue.IsUnknown() {
+		return
+	}
+
+	for _, name := range []string{"net", "net1", "net2", "net3", "net4", "net5", "net6", "net7"} {
+		var plan, state types.Object
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root(name), &plan)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root(name), &state)...)
+		if resp.Diagnostics.HasError() || !plan.Equal(state) {
+			return
+		}
+	}
+
+	resp.PlanValue = req.StateValue
+}
+
+func UseStateForUnknownUnlessNetsChange() planmodifier.Map {
+	return useStateUnlessNetsChangeModifier{}
+}
+
+var _ planmodifier.String = useStateUnlessSiblingChangesModifier{}
+
+// useStateUnlessSiblingChangesModifier keeps a computed value of a nested object as it is in state,
+// like UseStateForUnknown, unless the string attribute next to it called sibling changes.
+type useStateUnlessSiblingChangesModifier struct {
+	sibling string
+}
+
+func (m useStateUnlessSiblingChangesModifier) Description(_ context.Context) string {
+	return "Once set, the value of this attribute in state will not change unless " + m.sibling + " changes."
+}
+
+func (m useStateUnlessSiblingChangesModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m useStateUnlessSiblingChangesModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.StateValue.IsNull() || !req.PlanValue.IsUnknown() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	siblingPath := req.Path.ParentPath().AtName(m.sibling)
+	var plan, state types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, siblingPath, &plan)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, siblingPath, &state)...)
+	if resp.Diagnostics.HasError() || !plan.Equal(state) {
+		return
+	}
+
+	resp.PlanValue = req.StateValue
+}
+
+func UseStateForUnknownUnlessSiblingChanges(sibling string) planmodifier.String {
+	return useStateUnlessSiblingChangesModifier{sibling}
+}
+
+var _ planmodifier.String = requiresReplaceUnlessImportedModifier{}
+
+// requiresReplaceUnlessImportedModifier requires replacing the guest when the value changes, except for
+// guests that were imported. Options only used when creating the guest can't be read back, so an
+// imported guest has them null in state and setting them in config shouldn't recreate it. A guest is
+// taken to be imported when the required attribute at marker, set on every create, is null in state.
+type requiresReplaceUnlessImportedModifier struct {
+	marker path.Path
+}
+
+func (m requiresReplaceUnlessImportedModifier) Description(_ context.Context) string {
+	return "If the value of this attribute changes, Terraform will destroy and recreate the resource, unless it was imported."
+}
+
+func (m requiresReplaceUnlessImportedModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m requiresReplaceUnlessImportedModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// nothing to replace when creating or destroying
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || req.PlanValue.Equal(req.StateValue) {
+		return
+	}
+
+	var marker types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, m.marker, &marker)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.RequiresReplace = !marker.IsNull()
+}
+
+func RequiresReplaceUnlessImported(marker path.Path) planmodifier.String {
+	return requiresReplaceUnlessImportedModifier{marker}
+}
+
+// planDefaultNode plans the provider's default node for a guest that doesn't set node itself. Like
+// for a configured node, a guest found on another node is left there if ignore_node_drift is set.
+func planDefaultNode(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, defaultNode string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// nothing to plan when destroying
+	if req.Plan.Raw.IsNull() {
+		return diags
+	}
+
+	var node types.String
+	diags.Append(req.Config.GetAttribute(ctx, path.Root("node"), &node)...)
+	if diags.HasError() || !node.IsNull() {
+		return diags
+	}
+
+	if defaultNode == "" {
+		diags.AddAttributeError(
+			path.Root("node"),
+			"Missing Node",
+			"node must be set, either on the resource or as default_node of the provider.",
+		)
+		return diags
+	}
+
+	var state types.String
+	var ignore types.Bool
+	if !req.State.Raw.IsNull() {
+		diags.Append(req.State.GetAttribute(ctx, path.Root("node"), &state)...)
+		diags.Append(req.Config.GetAttribute(ctx, path.Root("ignore_node_drift"), &ignore)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	if !state.IsNull() && ignore.ValueBool() {
+		diags.Append(resp.Plan.SetAttribute(ctx, path.Root("node"), state)...)
+	} else {
+		diags.Append(resp.Plan.SetAttribute(ctx, path.Root("node"), types.StringValue(defaultNode))...)
+	}
+
+	return diags
+}
@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+// moveGuestToPool moves the guest from pool from to pool to, either of which can be "" for no pool.
+// The API client has UpdateVMPool for this but it swallows errors, and relies on the VmRef knowing
+// the pool the guest is currently in.
+func moveGuestToPool(client *pveapi.Client, vmid int, from string, to string) error {
+	if from == to {
+		return nil
+	}
+
+	if from != "" {
+		err := client.Put(map[string]any{"vms": vmid, "delete": 1}, "/pools/"+from)
+		if err != nil {
+			return fmt.Errorf("unable to remove guest %d from pool '%s': %w", vmid, from, err)
+		}
+	}
+
+	if to != "" {
+		err := client.Put(map[string]any{"vms": vmid}, "/pools/"+to)
+		if err != nil {
+			return fmt.Errorf("unable to add guest %d to pool '%s': %w", vmid, to, err)
+		}
+	}
+
+	return nil
+}
+
+// poolValue converts the pool of a guest, as known by a VmRef the API client has looked up, to a
+// state value.
+func poolValue(vmr *pveapi.VmRef) types.String {
+	if vmr.Pool() == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(vmr.Pool())
+}
@@ -0,0 +1,199 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+var (
+	_ resource.Resource                = &poolResource{}
+	_ resource.ResourceWithConfigure   = &poolResource{}
+	_ resource.ResourceWithImportState = &poolResource{}
+)
+
+// poolIDRe is what PVE accepts as pool ID.
+var poolIDRe = regexp.MustCompile(`^[A-Za-z0-9.\-_]+$`)
+
+func NewPoolResource() resource.Resource {
+	return &poolResource{}
+}
+
+type poolResource struct {
+	client *pveapi.Client
+}
+
+type poolResourceModel struct {
+	PoolID  types.String `tfsdk:"poolid"`
+	Comment types.String `tfsdk:"comment"`
+}
+
+func (*poolResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pool"
+}
+
+func (*poolResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "This resource manages a Proxmox resource pool. Guests are put in it with their pool attribute.",
+		Attributes: map[string]schema.Attribute{
+			"poolid": schema.StringAttribute{
+				Description: "The ID of the pool. Changing it makes a new pool, which fails while guests are still in the old one.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(poolIDRe, "must be letters, digits, ., - or _ only"),
+				},
+			},
+			"comment": schema.StringAttribute{
+				Description: "Description of the pool.",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+func (r *poolResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*proxmoxProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			fmt.Sprintf("Expected %T, got: %T. Please report this to the provider developers.", data, req.ProviderData),
+		)
+		return
+	}
+
+	r.client = data.client
+}
+
+func (r *poolResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan poolResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Trace(ctx, fmt.Sprintf("Creating pool %s", plan.PoolID.ValueString()))
+	err := r.client.CreatePool(plan.PoolID.ValueString(), plan.Comment.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Pool",
+			fmt.Sprintf("Could not create pool %s, unexpected error: %s", plan.PoolID.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *poolResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state poolResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	pool, err := findPool(r.client, state.PoolID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Pool",
+			fmt.Sprintf("Could not list pools, unexpected error: %s", err.Error()),
+		)
+		return
+	}
+	if pool == nil {
+		tflog.Trace(ctx, fmt.Sprintf("Pool %s doesn't exist", state.PoolID.ValueString()))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if comment, _ := pool["comment"].(string); comment != "" {
+		state.Comment = types.StringValue(comment)
+	} else {
+		state.Comment = types.StringNull()
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *poolResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan poolResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// only comment can change without replacing the pool
+	err := r.client.UpdatePoolComment(plan.PoolID.ValueString(), plan.Comment.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Pool",
+			fmt.Sprintf("Could not update comment of pool %s, unexpected error: %s", plan.PoolID.ValueString(), err.Error()),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *poolResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state poolResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+
+	err := r.client.DeletePool(state.PoolID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting Pool",
+			fmt.Sprintf("Could not delete pool %s, unexpected error: %s", state.PoolID.ValueString(), err.Error()),
+		)
+		return
+	}
+	tflog.Trace(ctx, fmt.Sprintf("Pool %s deleted", state.PoolID.ValueString()))
+}
+
+func (*poolResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("poolid"), req, resp)
+}
+
+// findPool returns the pool as listed by the API, or nil if there's no pool with the ID.
+func findPool(client *pveapi.Client, poolid string) (map[string]any, error) {
+	pools, err := client.GetPoolList()
+	if err != nil {
+		return nil, err
+	}
+
+	list, _ := pools["data"].([]any)
+	for _, p := range list {
+		if m, ok := p.(map[string]any); ok && m["poolid"] == poolid {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
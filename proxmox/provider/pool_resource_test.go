@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/mollstam/terraform-provider-proxmox/proxmox/provider/testutil"
+)
+
+func TestAccPoolResource_CreateUpdateAndDelete(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testCheckPoolInPve("test-pool", false, ""),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_pool" "test" {
+	poolid  = "test-pool"
+	comment = "Pool for tests"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("proxmox_pool.test", "poolid", "test-pool"),
+					testCheckPoolInPve("test-pool", true, "Pool for tests"),
+				),
+			},
+			{
+				ResourceName:      "proxmox_pool.test",
+				ImportState:       true,
+				ImportStateId:     "test-pool",
+				ImportStateVerify: true,
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_pool" "test" {
+	poolid  = "test-pool"
+	comment = "Still a pool for tests"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("proxmox_pool.test", "comment", "Still a pool for tests"),
+					testCheckPoolInPve("test-pool", true, "Still a pool for tests"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_pool" "test" {
+	poolid = "test-pool"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckNoResourceAttr("proxmox_pool.test", "comment"),
+					testCheckPoolInPve("test-pool", true, ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPoolResource_WithVMInPool(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_pool" "test" {
+	poolid = "test-pool"
+}
+
+resource "proxmox_vm" "test" {
+	node = "pve"
+	pool = proxmox_pool.test.poolid
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("proxmox_vm.test", "pool", "test-pool"),
+					testCheckPoolInPve("test-pool", true, ""),
+				),
+			},
+		},
+	})
+}
+
+// testCheckPoolInPve checks if the pool exists in PVE and, if it should, that it has the comment.
+func testCheckPoolInPve(poolid string, exists bool, comment string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		pool, err := findPool(testutil.TestClient, poolid)
+		if err != nil {
+			return err
+		}
+
+		if (pool != nil) != exists {
+			return fmt.Errorf("expected pool %s to exist: %t, got %t", poolid, exists, pool != nil)
+		}
+		if pool == nil {
+			return nil
+		}
+
+		if c, _ := pool["comment"].(string); c != comment {
+			return fmt.Errorf("expected pool %s to have comment '%s', got '%s'", poolid, comment, c)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+// guestTaskSlack is how much longer than the timeout given to PVE a status task is waited for, PVE
+// fails the task itself when its timeout passes and that's the error worth reporting.
+const guestTaskSlack = 30 * time.Second
+
+// stopVM stops the VM according to mode. With stopModeShutdown the guest OS is asked to shut down
+// and given the provider timeout to do so, if it doesn't (e.g. it ignores ACPI or is hung) the VM is
+// stopped anyway.
+func stopVM(ctx context.Context, client *pveapi.Client, vmr *pveapi.VmRef, mode string) error {
+	if mode == stopModeShutdown {
+		err := guestStatusTask(ctx, client, vmr, vmTypeQemu, "shutdown")
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		tflog.Warn(ctx, fmt.Sprintf("Graceful shutdown of VM %d failed, stopping it instead: %s", vmr.VmId(), err.Error()))
+	}
+
+	_, err := client.StopVm(vmr)
+	return err
+}
+
+// rebootGuest reboots the guest so that pending changes are applied. The guest OS is asked to reboot
+// and given the provider timeout to do so, only if that fails is the guest stopped and started again.
+func rebootGuest(ctx context.Context, client *pveapi.Client, vmr *pveapi.VmRef, vmType string) error {
+	err := guestStatusTask(ctx, client, vmr, vmType, "reboot")
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return err
+	}
+	tflog.Warn(ctx, fmt.Sprintf("Reboot of guest %d failed, stopping and starting it instead: %s", vmr.VmId(), err.Error()))
+
+	_, err = client.StopVm(vmr)
+	if err != nil {
+		return fmt.Errorf("unable to stop guest: %w", err)
+	}
+	_, err = client.StartVm(vmr)
+	if err != nil {
+		return fmt.Errorf("unable to start guest: %w", err)
+	}
+	return nil
+}
+
+// pendingAfterRebootKey is the private state key the config keys still pending after the guest was last
+// rebooted to apply changes are kept under.
+const pendingAfterRebootKey = "pending_after_reboot"
+
+// privateState is the private state of a resource, as in the requests and responses of the framework.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// checkPendingAfterReboot warns about changes still pending after the guest has been rebooted to apply
+// them. These are changes the reboot can't clear, rebooting again (e.g. on the next apply) wouldn't
+// either, so they're reported for the user to sort out instead and remembered in private, see
+// skipRepeatedReboot.
+func checkPendingAfterReboot(ctx context.Context, client *pveapi.Client, vmr *pveapi.VmRef, vmType string, kind string, private privateState) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	keys, err := pendingGuestKeys(client, vmr, vmType)
+	if err != nil {
+		diags.AddWarning(
+			"Could Not Check Pending Changes",
+			fmt.Sprintf("Could not check if %s %d has changes left pending after rebooting it, unexpected error: %s", kind, vmr.VmId(), err.Error()),
+		)
+		return diags
+	}
+	diags.Append(rememberPendingAfterReboot(ctx, private, keys)...)
+	if len(keys) == 0 {
+		return diags
+	}
+
+	tflog.Warn(ctx, fmt.Sprintf("%s %d still has pending changes after reboot", kind, vmr.VmId()), map[string]any{"keys": keys})
+	diags.AddWarning(
+		"Changes Still Pending After Reboot",
+		fmt.Sprintf("%s %d was rebooted to apply changes, but changes to %s are still pending. Another reboot won't apply them, check the pending changes of the guest in PVE.", kind, vmr.VmId(), strings.Join(keys, ", ")),
+	)
+
+	return diags
+}
+
+// skipRepeatedReboot tells if the guest, which has pending changes, should not be rebooted to apply
+// them because the very same keys were still pending after the last reboot. Rebooting again wouldn't
+// apply them either and without this the guest would be rebooted on every apply.
+func skipRepeatedReboot(ctx context.Context, client *pveapi.Client, vmr *pveapi.VmRef, vmType string, kind string, private privateState) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	keys, err := pendingGuestKeys(client, vmr, vmType)
+	if err != nil {
+		// rebooting once too often beats never applying the changes
+		tflog.Warn(ctx, fmt.Sprintf("Could not check pending changes of %s %d, rebooting it: %s", kind, vmr.VmId(), err.Error()))
+		return false, diags
+	}
+
+	skip, d := pendingSameAsAfterReboot(ctx, private, keys)
+	diags.Append(d...)
+	if !skip {
+		return false, diags
+	}
+
+	tflog.Warn(ctx, fmt.Sprintf("Not rebooting %s %d, the same changes were still pending after the last reboot", kind, vmr.VmId()), map[string]any{"keys": keys})
+	diags.AddWarning(
+		"Changes Still Pending",
+		fmt.Sprintf("%s %d has pending changes to %s, which were still pending after it was last rebooted to apply them, so it wasn't rebooted again. Check the pending changes of the guest in PVE.", kind, vmr.VmId(), strings.Join(keys, ", ")),
+	)
+
+	return true, diags
+}
+
+// rememberPendingAfterReboot keeps keys, the sorted config keys still pending after a reboot, in
+// private. No keys clears what was kept before.
+func rememberPendingAfterReboot(ctx context.Context, private privateState, keys []string) diag.Diagnostics {
+	if len(keys) == 0 {
+		return private.SetKey(ctx, pendingAfterRebootKey, nil)
+	}
+
+	b, err := json.Marshal(keys)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Error Storing Pending Changes", "Could not encode pending config keys, unexpected error: "+err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, pendingAfterRebootKey, b)
+}
+
+// pendingSameAsAfterReboot tells if keys, the sorted config keys pending now, are the ones kept in
+// private as still pending after the last reboot.
+func pendingSameAsAfterReboot(ctx context.Context, private privateState, keys []string) (bool, diag.Diagnostics) {
+	b, diags := private.GetKey(ctx, pendingAfterRebootKey)
+	if diags.HasError() || len(b) == 0 {
+		return false, diags
+	}
+
+	var remembered []string
+	if err := json.Unmarshal(b, &remembered); err != nil {
+		// not worth failing the apply over, it only means rebooting once more
+		tflog.Warn(ctx, "Could not decode config keys pending after last reboot: "+err.Error())
+		return false, diags
+	}
+	return slices.Equal(remembered, keys), diags
+}
+
+// pendingGuestKeys returns the config keys of the guest with pending changes, sorted.
+func pendingGuestKeys(client *pveapi.Client, vmr *pveapi.VmRef, vmType string) ([]string, error) {
+	list, err := client.GetItemListInterfaceArray(fmt.Sprintf("/nodes/%s/%s/%d/pending", vmr.Node(), vmType, vmr.VmId()))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	for _, i := range list {
+		p, ok := i.(map[string]any)
+		if !ok {
+			continue
+		}
+		_, pending := p["pending"]
+		_, deleted := p["delete"]
+		if key, ok := p["key"].(string); ok && (pending || deleted) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// guestStatusTask runs a status change like shutdown or reboot, which PVE waits for the guest OS to
+// carry out, with the provider timeout. Unlike StatusChangeVm of the API client it isn't retried, a
+// guest not reacting to the first request won't react to the next.
+func guestStatusTask(ctx context.Context, client *pveapi.Client, vmr *pveapi.VmRef, vmType string, action string) error {
+	params := map[string]any{"timeout": client.TaskTimeout}
+	body, err := client.CreateItemReturnStatus(params, fmt.Sprintf("/nodes/%s/%s/%d/status/%s", vmr.Node(), vmType, vmr.VmId(), action))
+	if err != nil {
+		return err
+	}
+
+	upid, err := upidFromResponse(body)
+	if err != nil {
+		return err
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("Waiting up to %ds for %s of guest %d", client.TaskTimeout, action, vmr.VmId()))
+	return waitForTask(ctx, client, upid, time.Duration(client.TaskTimeout)*time.Second+guestTaskSlack)
+}
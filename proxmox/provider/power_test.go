@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+type testPrivateState map[string][]byte
+
+func (p testPrivateState) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func (p testPrivateState) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	if len(value) == 0 {
+		delete(p, key)
+	} else {
+		p[key] = value
+	}
+	return nil
+}
+
+func TestPendingSameAsAfterReboot(t *testing.T) {
+	ctx := context.Background()
+	private := testPrivateState{}
+
+	same, diags := pendingSameAsAfterReboot(ctx, private, []string{"cores"})
+	if diags.HasError() || same {
+		t.Errorf("expected nothing remembered to not match, got %v %v", same, diags)
+	}
+
+	diags = rememberPendingAfterReboot(ctx, private, []string{"cores", "memory"})
+	if diags.HasError() {
+		t.Fatalf("unexpected error remembering keys: %v", diags)
+	}
+
+	for _, tc := range []struct {
+		keys     []string
+		expected bool
+	}{
+		{[]string{"cores", "memory"}, true},
+		{[]string{"cores"}, false},
+		{[]string{"cores", "memory", "net0"}, false},
+		{[]string{}, false},
+	} {
+		same, diags := pendingSameAsAfterReboot(ctx, private, tc.keys)
+		if diags.HasError() {
+			t.Fatalf("unexpected error for %v: %v", tc.keys, diags)
+		}
+		if same != tc.expected {
+			t.Errorf("expected %v for %v, got %v", tc.expected, tc.keys, same)
+		}
+	}
+
+	diags = rememberPendingAfterReboot(ctx, private, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected error clearing keys: %v", diags)
+	}
+	if _, ok := private[pendingAfterRebootKey]; ok {
+		t.Errorf("expected no keys to clear what was remembered, got %q", private[pendingAfterRebootKey])
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	pschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
@@ -27,6 +28,7 @@ import (
 const defaultTLSInsecure = false
 const defaultTimeout = 60
 const defaultDebug = false
+const defaultSkipPermissionCheck = false
 
 func New(version string) func() provider.Provider {
 	return func() provider.Provider {
@@ -40,15 +42,26 @@ type proxmoxProvider struct {
 	version string
 }
 
+// proxmoxProviderData is handed to resources when they're configured.
+type proxmoxProviderData struct {
+	client      *pveapi.Client
+	defaultNode string
+	templates   *vmRefCache
+}
+
 type proxmoxProviderModel struct {
-	APIURL         types.String `tfsdk:"api_url"`
-	APITokenID     types.String `tfsdk:"api_token_id"`
-	APITokenSecret types.String `tfsdk:"api_token_secret"`
-	TLSInsecure    types.Bool   `tfsdk:"tls_insecure"`
-	HTTPHeaders    types.String `tfsdk:"http_headers"`
-	Timeout        types.Int64  `tfsdk:"timeout"`
-	Debug          types.Bool   `tfsdk:"debug"`
-	ProxyServer    types.String `tfsdk:"proxy_server"`
+	APIURL              types.String `tfsdk:"api_url"`
+	APITokenID          types.String `tfsdk:"api_token_id"`
+	APITokenSecret      types.String `tfsdk:"api_token_secret"`
+	APIUser             types.String `tfsdk:"api_user"`
+	APIPassword         types.String `tfsdk:"api_password"`
+	TLSInsecure         types.Bool   `tfsdk:"tls_insecure"`
+	HTTPHeaders         types.String `tfsdk:"http_headers"`
+	Timeout             types.Int64  `tfsdk:"timeout"`
+	Debug               types.Bool   `tfsdk:"debug"`
+	ProxyServer         types.String `tfsdk:"proxy_server"`
+	DefaultNode         types.String `tfsdk:"default_node"`
+	SkipPermissionCheck types.Bool   `tfsdk:"skip_permission_check"`
 }
 
 func (p *proxmoxProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -76,6 +89,15 @@ func (*proxmoxProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp
 				Description: "API token secret e.g. 3b5a972d-bdb2-4181-b8f2-e3cdb34b3b4f",
 				Sensitive:   true,
 			},
+			"api_user": rschema.StringAttribute{
+				Optional:    true,
+				Description: "User to log in as with api_password when no API token is given, e.g. terraform@pam",
+			},
+			"api_password": rschema.StringAttribute{
+				Optional:    true,
+				Description: "Password of api_user",
+				Sensitive:   true,
+			},
 			"tls_insecure": rschema.BoolAttribute{
 				Optional:    true,
 				Default:     booldefault.StaticBool(defaultTLSInsecure),
@@ -96,7 +118,7 @@ func (*proxmoxProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp
 				Optional:    true,
 				Default:     booldefault.StaticBool(defaultDebug),
 				Computed:    true,
-				Description: "Enable or disable the verbose debug output from the API client",
+				Description: "Log full API requests and responses, with credentials redacted, at debug level in the provider's api log subsystem (e.g. TF_LOG_PROVIDER_PROXMOX_API=DEBUG)",
 			},
 			"proxy_server": rschema.StringAttribute{
 				Optional:    true,
@@ -105,6 +127,16 @@ func (*proxmoxProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp
 					URLValidator("you must specify a valid URL for the proxy server"),
 				},
 			},
+			"default_node": rschema.StringAttribute{
+				Optional:    true,
+				Description: "Cluster node used by resources that don't set node themselves, e.g. pve",
+			},
+			"skip_permission_check": rschema.BoolAttribute{
+				Optional:    true,
+				Default:     booldefault.StaticBool(defaultSkipPermissionCheck),
+				Computed:    true,
+				Description: "Don't check that the API user has the permissions the provider needs when configuring it, for roles that deliberately leave some of them out",
+			},
 		},
 	}
 }
@@ -146,6 +178,24 @@ func (*proxmoxProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		)
 	}
 
+	if config.APIUser.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_user"),
+			"Unknown Proxmox VE API User",
+			"The provider cannot create the API client as api_user is set to an unknown configuration value. "+
+				"Either target apply the source of the value first, set the value statically, or use the PVE_USER environment variable.",
+		)
+	}
+
+	if config.APIPassword.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_password"),
+			"Unknown Proxmox VE API Password",
+			"The provider cannot create the API client as api_password is set to an unknown configuration value. "+
+				"Either target apply the source of the value first, set the value statically, or use the PVE_PASSWORD environment variable.",
+		)
+	}
+
 	if config.TLSInsecure.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("tls_insecure"),
@@ -182,6 +232,33 @@ func (*proxmoxProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		)
 	}
 
+	if config.ProxyServer.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("proxy_server"),
+			"Unknown Proxmox VE Proxy Server",
+			"The provider cannot create the API client as proxy_server is set to an unknown configuration value. "+
+				"Either target apply the source of the value first, set the value statically, or use the PVE_PROXY_SERVER environment variable.",
+		)
+	}
+
+	if config.DefaultNode.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("default_node"),
+			"Unknown Proxmox VE Default Node",
+			"The provider cannot be configured as default_node is set to an unknown configuration value. "+
+				"Either target apply the source of the value first, set the value statically, or use the PVE_DEFAULT_NODE environment variable.",
+		)
+	}
+
+	if config.SkipPermissionCheck.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("skip_permission_check"),
+			"Unknown Proxmox VE Skip Permission Check",
+			"The provider cannot be configured as skip_permission_check is set to an unknown configuration value. "+
+				"Either target apply the source of the value first, set the value statically, or use the PVE_SKIP_PERMISSION_CHECK environment variable.",
+		)
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -201,6 +278,16 @@ func (*proxmoxProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		apiTokenSecret = config.APITokenSecret.ValueString()
 	}
 
+	apiUser := os.Getenv("PVE_USER")
+	if !config.APIUser.IsNull() {
+		apiUser = config.APIUser.ValueString()
+	}
+
+	apiPassword := os.Getenv("PVE_PASSWORD")
+	if !config.APIPassword.IsNull() {
+		apiPassword = config.APIPassword.ValueString()
+	}
+
 	tlsInsecure := GetenvOrDefaultBool("PVE_TLS_INSECURE", defaultTLSInsecure)
 	if !config.TLSInsecure.IsNull() {
 		tlsInsecure = config.TLSInsecure.ValueBool()
@@ -233,6 +320,30 @@ func (*proxmoxProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		proxyServer = config.ProxyServer.ValueString()
 	}
 
+	defaultNode := os.Getenv("PVE_DEFAULT_NODE")
+	if !config.DefaultNode.IsNull() {
+		defaultNode = config.DefaultNode.ValueString()
+	}
+
+	skipPermissionCheck := GetenvOrDefaultBool("PVE_SKIP_PERMISSION_CHECK", defaultSkipPermissionCheck)
+	if !config.SkipPermissionCheck.IsNull() {
+		skipPermissionCheck = config.SkipPermissionCheck.ValueBool()
+	}
+
+	// credentials in config decide how to authenticate, the environment only does when config has none,
+	// so that e.g. a token in the environment doesn't take over from a user and password in config
+	useToken := apiTokenID != ""
+	if !config.APITokenID.IsNull() {
+		useToken = true
+	} else if !config.APIUser.IsNull() {
+		useToken = false
+	}
+	if useToken {
+		apiUser, apiPassword = "", ""
+	} else {
+		apiTokenID, apiTokenSecret = "", ""
+	}
+
 	if apiTokenID != "" && !strings.Contains(apiTokenID, "!") {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("api_token_id"),
@@ -241,6 +352,13 @@ func (*proxmoxProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		)
 	}
 
+	if apiTokenID == "" && apiUser == "" {
+		resp.Diagnostics.AddError(
+			"Missing API Credentials",
+			"Either an API token (api_token_id and api_token_secret) or a user and password (api_user and api_password) must be given, in config or through the PVE_API_TOKEN_ID/PVE_API_TOKEN_SECRET or PVE_USER/PVE_PASSWORD environment variables.",
+		)
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -254,9 +372,12 @@ func (*proxmoxProvider) Configure(ctx context.Context, req provider.ConfigureReq
 	}
 
 	client, err := newProxmoxClient(
+		ctx,
 		apiURL,
 		apiTokenID,
 		apiTokenSecret,
+		apiUser,
+		apiPassword,
 		tlsConf,
 		httpHeaders,
 		int(timeout),
@@ -279,6 +400,55 @@ func (*proxmoxProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		return
 	}
 
+	if skipPermissionCheck {
+		tflog.Warn(ctx, "Skipping the check of API user permissions as skip_permission_check is set, operations may fail on missing permissions instead")
+	} else {
+		// a token has the permissions of its user at most, so check those of the user
+		id := apiUser
+		if apiTokenID != "" {
+			id = strings.Split(apiTokenID, "!")[0]
+		}
+		resp.Diagnostics.Append(checkUserPermissions(client, id)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(checkClusterNodes(client, defaultNode)...)
+
+	data := &proxmoxProviderData{
+		client:      client,
+		defaultNode: defaultNode,
+		templates:   newVMRefCache(),
+	}
+	resp.DataSourceData = data
+	resp.ResourceData = data
+
+	tflog.Debug(ctx, "Configured Proxmox VE provider", map[string]any{"success": true})
+}
+
+func (*proxmoxProvider) Resources(_ context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		NewVMResource,
+		NewLXCResource,
+		NewVMSnapshotResource,
+		NewPoolResource,
+	}
+}
+
+func (*proxmoxProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{
+		NewNodeDataSource,
+		NewVMsDataSource,
+		NewTemplateDataSource,
+	}
+}
+
+// checkUserPermissions makes sure the user with id has at least the permissions the provider needs
+// on /, so that missing ones are reported up front instead of failing halfway through an apply.
+func checkUserPermissions(client *pveapi.Client, id string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
 	minimumPermissions := []string{
 		"Datastore.AllocateSpace",
 		"Datastore.Audit",
@@ -301,20 +471,19 @@ func (*proxmoxProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		"VM.Monitor",
 		"VM.PowerMgmt",
 	}
-	id := strings.Split(apiTokenID, "!")[0]
 	userID, err := pveapi.NewUserID(id)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Failed to create API client",
 			"Unexpected error when creating UserID object for the Proxmox API client, if not clear please contact the provider developers.\n\n"+err.Error())
-		return
+		return diags
 	}
 	permlist, err := client.GetUserPermissions(userID, "/")
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Failed to create API client",
 			"Unexpected error when checking API user permissions, if not clear please contact the provider developers.\n\n"+err.Error())
-		return
+		return diags
 	}
 	sort.Strings(permlist)
 	sort.Strings(minimumPermissions)
@@ -333,53 +502,60 @@ func (*proxmoxProvider) Configure(ctx context.Context, req provider.ConfigureReq
 		}
 	}
 	if len(permDiff) != 0 {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Failed to create API client",
-			fmt.Sprintf("Permissions for user/token %s are not sufficient, please provide also the following permissions that are missing: %v", userID.ToString(), permDiff))
-		return
-	}
-
-	resp.DataSourceData = client
-	resp.ResourceData = client
-
-	tflog.Debug(ctx, "Configured Proxmox VE provider", map[string]any{"success": true})
-}
-
-func (*proxmoxProvider) Resources(_ context.Context) []func() resource.Resource {
-	return []func() resource.Resource{
-		NewVMResource,
-		NewLXCResource,
+			fmt.Sprintf("Permissions for user/token %s are not sufficient, please provide also the following permissions that are missing: %v. "+
+				"If your role deliberately lacks some of them, the check can be turned off with skip_permission_check.", userID.ToString(), permDiff))
 	}
-}
 
-func (*proxmoxProvider) DataSources(_ context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return diags
 }
 
-func newProxmoxClient(apiURL string,
+func newProxmoxClient(ctx context.Context,
+	apiURL string,
 	apiTokenID string,
 	apiTokenSecret string,
+	apiUser string,
+	apiPassword string,
 	tlsConf *tls.Config,
 	httpHeaders string,
 	timeout int,
 	debug bool,
 	proxyServer string) (*pveapi.Client, error) {
+	// Configure has already picked either a token or a user and password to authenticate with, and
+	// checked the format of the token ID
+	useToken := apiTokenID != ""
+
 	var err error
-	if apiTokenSecret == "" {
+	if useToken && apiTokenSecret == "" {
 		err = errors.New("API token secret not provided, must exist")
+	} else if !useToken && apiPassword == "" {
+		err = errors.New("API password not provided, must exist when logging in as api_user")
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	if !strings.Contains(apiTokenID, "!") {
-		err = errors.New("your API Token ID should contain a !, check your API credentials")
+	// the API client's own debug output is toggled by a package global, leave that alone and log
+	// through tflog from the HTTP client instead
+	hclient, err := newAPIHTTPClient(ctx, tlsConf, proxyServer, debug)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy server: %w", err)
 	}
+	client, err := pveapi.NewClient(apiURL, hclient, httpHeaders, tlsConf, proxyServer, timeout)
 	if err != nil {
 		return nil, err
 	}
 
-	client, _ := pveapi.NewClient(apiURL, nil, httpHeaders, tlsConf, proxyServer, timeout)
-	*pveapi.Debug = debug
+	if useToken {
+		client.SetAPIToken(apiTokenID, apiTokenSecret)
+		return client, nil
+	}
 
-	client.SetAPIToken(apiTokenID, apiTokenSecret)
+	err = client.Login(apiUser, apiPassword, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to log in as %s: %w", apiUser, err)
+	}
 
 	return client, nil
 }
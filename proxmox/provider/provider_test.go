@@ -1,8 +1,17 @@
 package provider
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 const (
@@ -17,6 +26,21 @@ provider "proxmox" {
 	debug = false
 	proxy_server = "http://127.0.0.1:8080"
 }
+`
+
+	providerConfigWithDefaultNode = `
+provider "proxmox" {
+	api_url = "https://127.0.0.1:8806/api2/json"
+	tls_insecure = true
+
+	api_token_id = "root@pam!tf"
+	api_token_secret = "897d5216-64c1-4da8-b6dc-33eed34a34a0"
+
+	debug = false
+	proxy_server = "http://127.0.0.1:8080"
+
+	default_node = "pve"
+}
 `
 )
 
@@ -25,3 +49,207 @@ var (
 		"proxmox": providerserver.NewProtocol6WithError(New("test")()),
 	}
 )
+
+func TestProviderConfigure_ProxyServerIsUsed(t *testing.T) {
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a request through a proxy has the absolute URL of the API
+		if r.URL.Host == "pve.invalid:8006" {
+			proxied.Add(1)
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "proxmox" {
+	api_url = "http://pve.invalid:8006/api2/json"
+
+	api_token_id = "root@pam!tf"
+	api_token_secret = "897d5216-64c1-4da8-b6dc-33eed34a34a0"
+
+	proxy_server = "` + proxy.URL + `"
+}
+
+data "proxmox_vms" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`sanity check failed`),
+			},
+		},
+	})
+
+	if proxied.Load() == 0 {
+		t.Error("expected the API to be called through proxy_server")
+	}
+}
+
+func TestProviderConfigure_LogsInWithPassword(t *testing.T) {
+	var loggedInAs atomic.Value
+	pve := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api2/json/access/ticket" && r.ParseForm() == nil {
+			loggedInAs.Store(r.PostForm.Get("username") + ":" + r.PostForm.Get("password"))
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer pve.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "proxmox" {
+	api_url = "` + pve.URL + `/api2/json"
+
+	api_user     = "terraform@pam"
+	api_password = "hunter2"
+}
+
+data "proxmox_vms" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`unable to log in as terraform@pam`),
+			},
+		},
+	})
+
+	if got, _ := loggedInAs.Load().(string); got != "terraform@pam:hunter2" {
+		t.Errorf("expected login as terraform@pam with the password, got %q", got)
+	}
+}
+
+func TestProviderConfigure_PasswordInConfigWinsOverTokenInEnv(t *testing.T) {
+	t.Setenv("PVE_API_TOKEN_ID", "root@pam!tf")
+	t.Setenv("PVE_API_TOKEN_SECRET", "897d5216-64c1-4da8-b6dc-33eed34a34a0")
+
+	var loggedInAs atomic.Value
+	pve := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api2/json/access/ticket" && r.ParseForm() == nil {
+			loggedInAs.Store(r.PostForm.Get("username"))
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer pve.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "proxmox" {
+	api_url = "` + pve.URL + `/api2/json"
+
+	api_user     = "terraform@pam"
+	api_password = "hunter2"
+}
+
+data "proxmox_vms" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`unable to log in as terraform@pam`),
+			},
+		},
+	})
+
+	if got, _ := loggedInAs.Load().(string); got != "terraform@pam" {
+		t.Errorf("expected login as terraform@pam rather than using the token from the environment, got %q", got)
+	}
+}
+
+func TestProviderConfigure_MissingCredentials_CausesError(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "proxmox" {
+	api_url = "https://127.0.0.1:8806/api2/json"
+}
+
+data "proxmox_vms" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`Missing API Credentials`),
+			},
+		},
+	})
+}
+
+// newLimitedPVEServer fakes an API that answers /version and an empty guest list, but refuses the
+// requests made to check the permissions of the user, as for a token with a limited role.
+func newLimitedPVEServer(permissionsChecked *atomic.Bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api2/json/version":
+			fmt.Fprint(w, `{"data":{"version":"8.1.4","release":"8.1","repoid":"ec5affc9"}}`)
+		case r.URL.Path == "/api2/json/cluster/resources":
+			fmt.Fprint(w, `{"data":[]}`)
+		case strings.HasPrefix(r.URL.Path, "/api2/json/access/"):
+			permissionsChecked.Store(true)
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+}
+
+func TestProviderConfigure_LimitedToken_CausesError(t *testing.T) {
+	var permissionsChecked atomic.Bool
+	pve := newLimitedPVEServer(&permissionsChecked)
+	defer pve.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "proxmox" {
+	api_url = "` + pve.URL + `/api2/json"
+
+	api_token_id = "limited@pve!tf"
+	api_token_secret = "897d5216-64c1-4da8-b6dc-33eed34a34a0"
+}
+
+data "proxmox_vms" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`Failed to create API client`),
+			},
+		},
+	})
+
+	if !permissionsChecked.Load() {
+		t.Error("expected the permissions of the user to be checked")
+	}
+}
+
+func TestProviderConfigure_SkipPermissionCheck(t *testing.T) {
+	var permissionsChecked atomic.Bool
+	pve := newLimitedPVEServer(&permissionsChecked)
+	defer pve.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "proxmox" {
+	api_url = "` + pve.URL + `/api2/json"
+
+	api_token_id = "limited@pve!tf"
+	api_token_secret = "897d5216-64c1-4da8-b6dc-33eed34a34a0"
+
+	skip_permission_check = true
+}
+
+data "proxmox_vms" "test" {}
+`,
+				Check: resource.TestCheckResourceAttr("data.proxmox_vms.test", "vms.#", "0"),
+			},
+		},
+	})
+
+	if permissionsChecked.Load() {
+		t.Error("expected the permissions of the user not to be checked with skip_permission_check set")
+	}
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+const (
+	statusReadAttempts  = 3
+	statusRetryInterval = time.Second
+)
+
+// guestStatus reads the status (running, stopped, ..) of a VM or LXC. Now and then PVE leaves the
+// status out of its reply, if so it's read again a few times before settling on an empty status rather
+// than failing the whole operation, see statusValue.
+func guestStatus(ctx context.Context, client *pveapi.Client, vmr *pveapi.VmRef) (string, error) {
+	for attempt := 1; ; attempt++ {
+		state, err := client.GetVmState(vmr)
+		if err != nil {
+			return "", err
+		}
+		if status, ok := state["status"].(string); ok && status != "" {
+			return status, nil
+		}
+
+		if attempt >= statusReadAttempts {
+			tflog.Warn(ctx, fmt.Sprintf("No status reported for guest %d, keeping the one known before", vmr.VmId()), map[string]any{"status": state["status"]})
+			return "", nil
+		}
+		tflog.Debug(ctx, fmt.Sprintf("No status reported for guest %d, reading it again", vmr.VmId()), map[string]any{"status": state["status"], "attempt": attempt})
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(statusRetryInterval):
+		}
+	}
+}
+
+// statusValue converts status, as read by guestStatus, to a state value. A status PVE didn't report
+// keeps the prior value, from state or the plan, since there's nothing better to replace it with.
+func statusValue(status string, prior types.String) types.String {
+	if status != "" {
+		return types.StringValue(status)
+	}
+	if prior.IsUnknown() {
+		return types.StringNull()
+	}
+	return prior
+}
@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestStatusValue(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		status   string
+		prior    types.String
+		expected types.String
+	}{
+		{"reported", stateStopped, types.StringValue(stateRunning), types.StringValue(stateStopped)},
+		{"reported without prior", stateRunning, types.StringNull(), types.StringValue(stateRunning)},
+		{"not reported keeps prior", "", types.StringValue(stateRunning), types.StringValue(stateRunning)},
+		{"not reported without prior", "", types.StringNull(), types.StringNull()},
+		{"not reported with unknown prior", "", types.StringUnknown(), types.StringNull()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := statusValue(tc.status, tc.prior); !got.Equal(tc.expected) {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
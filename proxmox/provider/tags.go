@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Tags are modeled as a set of strings while PVE stores them as a single string, the helpers here
+// convert between the two for all guest types alike.
+
+var tagRe = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_\-+.]*$`)
+
+// TagsValidators validates a set of tags as PVE accepts them.
+func TagsValidators() []validator.Set {
+	return []validator.Set{
+		setvalidator.SizeAtLeast(1),
+		setvalidator.ValueStringsAre(
+			stringvalidator.RegexMatches(tagRe, "must start with a letter, digit or underscore, followed by letters, digits, _, -, + or ."),
+		),
+	}
+}
+
+// splitTags splits the tags of a guest config. PVE stores them joined by semicolons but also accepts
+// commas and spaces, so any of those are taken as separators.
+func splitTags(tags string) []string {
+	return strings.FieldsFunc(tags, func(r rune) bool {
+		return r == ';' || r == ',' || r == ' '
+	})
+}
+
+// joinTags joins the tags in set the way PVE stores them, sorted and separated by semicolons.
+func joinTags(ctx context.Context, set types.Set) (string, error) {
+	var tags []string
+	diags := set.ElementsAs(ctx, &tags, false)
+	if diags.HasError() {
+		return "", errors.New("unable to read tags from model")
+	}
+	sort.Strings(tags)
+	return strings.Join(tags, ";"), nil
+}
+
+// tagsValue converts the tags of a guest config to a state value, null if there are none.
+func tagsValue(ctx context.Context, tags string) (types.Set, error) {
+	split := splitTags(tags)
+	if len(split) == 0 {
+		return types.SetNull(types.StringType), nil
+	}
+	v, diags := types.SetValueFrom(ctx, types.StringType, split)
+	if diags.HasError() {
+		return types.SetNull(types.StringType), errors.New("unexpected error when reading tags from config")
+	}
+	return v, nil
+}
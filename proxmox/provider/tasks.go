@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+const taskPollInterval = 2 * time.Second
+
+var upidNodeRe = regexp.MustCompile(`^UPID:([^:]+):`)
+
+// upidFromResponse reads the task UPID from the body of an API call that started a task.
+func upidFromResponse(body string) (string, error) {
+	var r struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &r); err != nil {
+		return "", fmt.Errorf("unable to read task from API response '%s': %w", body, err)
+	}
+	if !upidNodeRe.MatchString(r.Data) {
+		return "", fmt.Errorf("API response did not contain a task: %s", body)
+	}
+	return r.Data, nil
+}
+
+// waitForTask polls the task until it has stopped, logging the last line of the task log as it goes
+// so that long running tasks (like big clones) show some progress. Gives up when timeout passes or
+// ctx is done, whichever comes first. A task that stopped with an error is returned as an error.
+func waitForTask(ctx context.Context, client *pveapi.Client, upid string, timeout time.Duration) error {
+	m := upidNodeRe.FindStringSubmatch(upid)
+	if m == nil {
+		return fmt.Errorf("malformed task id '%s'", upid)
+	}
+	logURL := fmt.Sprintf("/nodes/%s/tasks/%s/log", m[1], url.PathEscape(upid))
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	logStart := 0
+	for {
+		exitStatus, err := client.GetTaskExitstatus(upid)
+		if err != nil && err != io.ErrUnexpectedEOF { // same as the API client, don't give up on ErrUnexpectedEOF
+			return err
+		}
+		if exitStatus != nil {
+			// failed tasks have the error as exit status, e.g. a clone onto storage missing on the node
+			if s, ok := exitStatus.(string); ok && s != "OK" && !strings.HasPrefix(s, "WARNINGS") {
+				return fmt.Errorf("task %s failed: %s", upid, s)
+			}
+			return nil
+		}
+
+		// only log the latest line since last poll, tasks like clones print a line per percent of progress
+		lines, err := client.GetItemListInterfaceArray(fmt.Sprintf("%s?start=%d", logURL, logStart))
+		if err == nil && len(lines) > 0 {
+			if l, ok := lines[len(lines)-1].(map[string]any); ok {
+				if n, ok := l["n"].(float64); ok {
+					logStart = int(n)
+				}
+				if line, ok := l["t"].(string); ok {
+					tflog.Info(ctx, line, map[string]any{"upid": upid})
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("timeout waiting for task %s to complete after %s", upid, timeout)
+			}
+			return ctx.Err()
+		case <-time.After(taskPollInterval):
+		}
+	}
+}
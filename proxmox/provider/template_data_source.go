@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+var (
+	_ datasource.DataSource              = &templateDataSource{}
+	_ datasource.DataSourceWithConfigure = &templateDataSource{}
+)
+
+func NewTemplateDataSource() datasource.DataSource {
+	return &templateDataSource{}
+}
+
+type templateDataSource struct {
+	client *pveapi.Client
+}
+
+type templateDataSourceModel struct {
+	Name types.String `tfsdk:"name"`
+	VMID types.Int64  `tfsdk:"vmid"`
+	Node types.String `tfsdk:"node"`
+}
+
+func (*templateDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_template"
+}
+
+func (*templateDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Looks up a VM template by name, e.g. to clone it by ID.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description: "The name of the template. It's an error if no template, or more than one, has the name.",
+				Required:    true,
+			},
+			"vmid": schema.Int64Attribute{
+				Description: "The ID of the template.",
+				Computed:    true,
+			},
+			"node": schema.StringAttribute{
+				Description: "The node the template is on.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *templateDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*proxmoxProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			fmt.Sprintf("Expected %T, got: %T. Please report this to the provider developers.", data, req.ProviderData),
+		)
+		return
+	}
+
+	d.client = data.client
+}
+
+func (d *templateDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config templateDataSourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// unlike the lookup by name when cloning, regular VMs with the name aren't matched
+	guests, err := pveapi.ListGuests(d.client)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Template",
+			"Could not list guests, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	var found []pveapi.GuestResource
+	for _, g := range guests {
+		if g.Template && g.Type == pveapi.GuestQemu && g.Name == config.Name.ValueString() {
+			found = append(found, g)
+		}
+	}
+
+	if len(found) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Template Not Found",
+			fmt.Sprintf("There is no VM template named '%s' in the cluster.", config.Name.ValueString()),
+		)
+		return
+	} else if len(found) > 1 {
+		ids := make([]string, len(found))
+		for i, g := range found {
+			ids[i] = strconv.Itoa(int(g.Id))
+		}
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Ambiguous Template Name",
+			fmt.Sprintf("There are several VM templates named '%s' in the cluster, with IDs %s. Rename them so the name is unique.", config.Name.ValueString(), strings.Join(ids, ", ")),
+		)
+		return
+	}
+
+	config.VMID = types.Int64Value(int64(found[0].Id))
+	config.Node = types.StringValue(found[0].Node)
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
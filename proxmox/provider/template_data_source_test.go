@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/mollstam/terraform-provider-proxmox/proxmox/provider/testutil"
+)
+
+func TestAccTemplateDataSource_ResolvesName(t *testing.T) {
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "proxmox_template" "test" {
+	name = "Test-Template-01"
+}
+
+resource "proxmox_vm" "test_clone" {
+	node  = data.proxmox_template.test.node
+	clone = tostring(data.proxmox_template.test.vmid)
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.proxmox_template.test", "vmid", "200"),
+					resource.TestCheckResourceAttr("data.proxmox_template.test", "node", "pve"),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "clone", "200"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTemplateDataSource_MissingTemplate_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "proxmox_template" "test" {
+	name = "Nonexistent-Template"
+}
+`,
+				ExpectError: regexp.MustCompile(`Template Not Found`),
+			},
+		},
+	})
+}
@@ -2,11 +2,17 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"net/netip"
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/helpers/validatordiag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
@@ -80,7 +86,7 @@ func (v diskSizeValidator) ValidateString(ctx context.Context, request validator
 	if val.Equal(types.StringValue("")) {
 		invalid = true
 	} else {
-		re := regexp.MustCompile(`^\d+[MG]?$`)
+		re := regexp.MustCompile(`^\d+[KMG]?$`)
 		invalid = !re.MatchString(val.ValueString())
 	}
 
@@ -122,24 +128,7 @@ func (v ipValidator) ValidateString(ctx context.Context, request validator.Strin
 	if val.Equal(types.StringValue("")) {
 		invalid = true
 	} else {
-		re := regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)\.(\d+)$`)
-		m := re.FindStringSubmatch(val.ValueString())
-		if m == nil {
-			invalid = true
-		} else {
-			if val, err := strconv.Atoi(m[1]); err != nil || val < 0 || val > 255 {
-				invalid = true
-			}
-			if val, err := strconv.Atoi(m[2]); err != nil || val < 0 || val > 255 {
-				invalid = true
-			}
-			if val, err := strconv.Atoi(m[3]); err != nil || val < 0 || val > 255 {
-				invalid = true
-			}
-			if val, err := strconv.Atoi(m[4]); err != nil || val < 0 || val > 255 {
-				invalid = true
-			}
-		}
+		invalid = !isIPv4(val.ValueString())
 	}
 
 	if invalid {
@@ -180,26 +169,147 @@ func (v ipCidrValidator) ValidateString(ctx context.Context, request validator.S
 	if val.Equal(types.StringValue("")) {
 		invalid = true
 	} else {
-		re := regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)\.(\d+)/(\d+)$`)
-		m := re.FindStringSubmatch(val.ValueString())
-		if m == nil {
-			invalid = true
-		} else {
-			if val, err := strconv.Atoi(m[1]); err != nil || val < 0 || val > 255 {
-				invalid = true
-			}
-			if val, err := strconv.Atoi(m[2]); err != nil || val < 0 || val > 255 {
-				invalid = true
-			}
-			if val, err := strconv.Atoi(m[3]); err != nil || val < 0 || val > 255 {
-				invalid = true
-			}
-			if val, err := strconv.Atoi(m[4]); err != nil || val < 0 || val > 255 {
+		invalid = !isIPv4Cidr(val.ValueString())
+	}
+
+	if invalid {
+		response.Diagnostics.Append(validatordiag.InvalidAttributeValueMatchDiagnostic(
+			request.Path,
+			v.Description(ctx),
+			val.String(),
+		))
+	}
+}
+
+func IPCidrValidator(description string) validator.String {
+	return ipCidrValidator{description}
+}
+
+var _ validator.String = ipconfigValidator{}
+
+type ipconfigValidator struct {
+	description string
+}
+
+func (v ipconfigValidator) Description(_ context.Context) string {
+	return v.description
+}
+
+func (v ipconfigValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v ipconfigValidator) ValidateString(ctx context.Context, request validator.StringRequest, response *validator.StringResponse) {
+	if request.ConfigValue.IsNull() || request.ConfigValue.IsUnknown() {
+		return
+	}
+
+	val := request.ConfigValue
+
+	invalid := false
+	if val.Equal(types.StringValue("")) {
+		invalid = true
+	} else {
+		for _, opt := range strings.Split(val.ValueString(), ",") {
+			key, value, _ := strings.Cut(opt, "=")
+			switch key {
+			case "ip":
+				invalid = invalid || (value != "dhcp" && !isIPv4Cidr(value))
+			case "gw":
+				invalid = invalid || !isIPv4(value)
+			case "ip6":
+				invalid = invalid || (value != "dhcp" && value != "auto" && !isIPv6Cidr(value))
+			case "gw6":
+				invalid = invalid || !isIPv6(value)
+			default:
 				invalid = true
 			}
-			if val, err := strconv.Atoi(m[5]); err != nil || val < 0 || val > 32 {
+		}
+	}
+
+	if invalid {
+		response.Diagnostics.Append(validatordiag.InvalidAttributeValueMatchDiagnostic(
+			request.Path,
+			v.Description(ctx),
+			val.String(),
+		))
+	}
+}
+
+func IpconfigValidator(description string) validator.String {
+	return ipconfigValidator{description}
+}
+
+var ipv4Re = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)\.(\d+)$`)
+
+func isIPv4(s string) bool {
+	m := ipv4Re.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	for _, octet := range m[1:] {
+		if val, err := strconv.Atoi(octet); err != nil || val < 0 || val > 255 {
+			return false
+		}
+	}
+	return true
+}
+
+func isIPv4Cidr(s string) bool {
+	ip, prefix, ok := strings.Cut(s, "/")
+	if !ok || !isIPv4(ip) {
+		return false
+	}
+	val, err := strconv.Atoi(prefix)
+	return err == nil && val >= 0 && val <= 32
+}
+
+func isIPv6(s string) bool {
+	addr, err := netip.ParseAddr(s)
+	return err == nil && addr.Is6() && addr.Zone() == ""
+}
+
+func isIPv6Cidr(s string) bool {
+	prefix, err := netip.ParsePrefix(s)
+	return err == nil && prefix.Addr().Is6()
+}
+
+var _ validator.String = startupValidator{}
+
+type startupValidator struct {
+	description string
+}
+
+func (v startupValidator) Description(_ context.Context) string {
+	return v.description
+}
+
+func (v startupValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v startupValidator) ValidateString(ctx context.Context, request validator.StringRequest, response *validator.StringResponse) {
+	if request.ConfigValue.IsNull() || request.ConfigValue.IsUnknown() {
+		return
+	}
+
+	val := request.ConfigValue
+
+	invalid := false
+	if val.Equal(types.StringValue("")) {
+		invalid = true
+	} else {
+		seen := map[string]bool{}
+		for _, opt := range strings.Split(val.ValueString(), ",") {
+			key, value, _ := strings.Cut(opt, "=")
+			switch key {
+			case "order", "up", "down":
+				n, err := strconv.Atoi(value)
+				invalid = invalid || seen[key] || err != nil || n < 0
+			default:
 				invalid = true
 			}
+			seen[key] = true
 		}
 	}
 
@@ -212,6 +322,451 @@ func (v ipCidrValidator) ValidateString(ctx context.Context, request validator.S
 	}
 }
 
-func IPCidrValidator(description string) validator.String {
-	return ipCidrValidator{description}
+func StartupValidator(description string) validator.String {
+	return startupValidator{description}
+}
+
+var _ validator.String = durationValidator{}
+
+type durationValidator struct {
+	description string
+}
+
+func (v durationValidator) Description(_ context.Context) string {
+	return v.description
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(ctx context.Context, request validator.StringRequest, response *validator.StringResponse) {
+	if request.ConfigValue.IsNull() || request.ConfigValue.IsUnknown() {
+		return
+	}
+
+	val := request.ConfigValue
+
+	d, err := time.ParseDuration(val.ValueString())
+	if err != nil || d <= 0 {
+		response.Diagnostics.Append(validatordiag.InvalidAttributeValueMatchDiagnostic(
+			request.Path,
+			v.Description(ctx),
+			val.String(),
+		))
+	}
+}
+
+func DurationValidator(description string) validator.String {
+	return durationValidator{description}
+}
+
+var _ validator.String = dnsNameValidator{}
+
+// dnsNameValidator checks that a value is a DNS name, i.e. dot separated RFC 1123 labels, which is
+// what PVE requires of VM names and container host names.
+type dnsNameValidator struct {
+	description string
+}
+
+func (v dnsNameValidator) Description(_ context.Context) string {
+	return v.description
+}
+
+func (v dnsNameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v dnsNameValidator) ValidateString(ctx context.Context, request validator.StringRequest, response *validator.StringResponse) {
+	if request.ConfigValue.IsNull() || request.ConfigValue.IsUnknown() {
+		return
+	}
+
+	val := request.ConfigValue
+
+	if !isDNSName(val.ValueString()) {
+		response.Diagnostics.Append(validatordiag.InvalidAttributeValueMatchDiagnostic(
+			request.Path,
+			v.Description(ctx),
+			val.String(),
+		))
+	}
+}
+
+func DNSNameValidator(description string) validator.String {
+	return dnsNameValidator{description}
+}
+
+var dnsLabelRe = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+func isDNSName(s string) bool {
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if !dnsLabelRe.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+var _ resource.ConfigValidator = balloonValidator{}
+
+// balloonValidator checks that the balloon target of a VM doesn't exceed its memory, and that shares
+// aren't given when ballooning is disabled since they'd have no effect.
+type balloonValidator struct{}
+
+func (v balloonValidator) Description(_ context.Context) string {
+	return "balloon can't exceed memory, and shares requires ballooning to be enabled"
+}
+
+func (v balloonValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v balloonValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var balloon, memory, shares types.Int64
+	var clone types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("balloon"), &balloon)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("memory"), &memory)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("shares"), &shares)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("clone"), &clone)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if balloon.IsNull() || balloon.IsUnknown() {
+		return
+	}
+
+	// without memory in config it's either the default or, for a clone, inherited from the template,
+	// so there's nothing reliable to compare against here
+	if clone.IsNull() && !memory.IsNull() && !memory.IsUnknown() && balloon.ValueInt64() > memory.ValueInt64() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("balloon"),
+			"Invalid Balloon",
+			fmt.Sprintf("balloon (%d) can't exceed memory (%d).", balloon.ValueInt64(), memory.ValueInt64()),
+		)
+	}
+
+	if balloon.ValueInt64() == 0 && !shares.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("shares"),
+			"Invalid Shares",
+			"shares has no effect when ballooning is disabled with balloon = 0, remove it or enable ballooning.",
+		)
+	}
+}
+
+func BalloonValidator() resource.ConfigValidator {
+	return balloonValidator{}
+}
+
+var _ resource.ConfigValidator = cloneFullValidator{}
+
+// cloneFullValidator checks that clone_storage and clone_snapshot aren't combined with clone_full = false,
+// PVE only takes a target storage or a snapshot for full clones.
+type cloneFullValidator struct{}
+
+func (v cloneFullValidator) Description(_ context.Context) string {
+	return "clone_storage and clone_snapshot can't be used with clone_full = false"
+}
+
+func (v cloneFullValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v cloneFullValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var full types.Bool
+	var storage, snapshot types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("clone_full"), &full)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("clone_storage"), &storage)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("clone_snapshot"), &snapshot)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if full.IsNull() || full.IsUnknown() || full.ValueBool() {
+		return
+	}
+
+	if !storage.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("clone_storage"),
+			"Invalid Attribute Combination",
+			"clone_storage can only be used with full clones, linked clones share the disks of the template. Remove it or set clone_full = true.",
+		)
+	}
+	if !snapshot.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("clone_snapshot"),
+			"Invalid Attribute Combination",
+			"clone_snapshot can only be used with full clones, linked clones are always of the template as it is. Remove it or set clone_full = true.",
+		)
+	}
+}
+
+func CloneFullValidator() resource.ConfigValidator {
+	return cloneFullValidator{}
+}
+
+var _ resource.ConfigValidator = vcpusValidator{}
+
+// vcpusValidator checks that the hotplugged vcpus of a VM don't exceed its sockets * cores. Both default
+// to 1 but are inherited from the template by clones, which are left to PVE.
+type vcpusValidator struct{}
+
+func (v vcpusValidator) Description(_ context.Context) string {
+	return "vcpus can't exceed sockets * cores"
+}
+
+func (v vcpusValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v vcpusValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var vcpus, sockets, cores types.Int64
+	var clone types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("vcpus"), &vcpus)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("sockets"), &sockets)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("cores"), &cores)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("clone"), &clone)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if vcpus.IsNull() || vcpus.IsUnknown() || sockets.IsUnknown() || cores.IsUnknown() {
+		return
+	}
+	if !clone.IsNull() && (sockets.IsNull() || cores.IsNull()) {
+		return
+	}
+
+	maxVcpus := int64(1)
+	if !sockets.IsNull() {
+		maxVcpus = sockets.ValueInt64()
+	}
+	if !cores.IsNull() {
+		maxVcpus *= cores.ValueInt64()
+	}
+	if vcpus.ValueInt64() > maxVcpus {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("vcpus"),
+			"Invalid vCPUs",
+			fmt.Sprintf("vcpus (%d) can't exceed sockets * cores (%d).", vcpus.ValueInt64(), maxVcpus),
+		)
+	}
+}
+
+func VcpusValidator() resource.ConfigValidator {
+	return vcpusValidator{}
+}
+
+var _ resource.ConfigValidator = efiDiskValidator{}
+
+// efiDiskValidator checks that a VM with bios ovmf has an efidisk for the EFI vars. Clones get the
+// efidisk of their template, if any.
+type efiDiskValidator struct{}
+
+func (v efiDiskValidator) Description(_ context.Context) string {
+	return "bios ovmf requires an efidisk"
+}
+
+func (v efiDiskValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v efiDiskValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var bios, clone types.String
+	var efidisk types.Object
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("bios"), &bios)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("efidisk"), &efidisk)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("clone"), &clone)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if bios.ValueString() == biosOVMF && efidisk.IsNull() && clone.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("efidisk"),
+			"Missing EFI Disk",
+			fmt.Sprintf("bios %s requires an efidisk to store the EFI vars in.", biosOVMF),
+		)
+	}
+}
+
+func EFIDiskValidator() resource.ConfigValidator {
+	return efiDiskValidator{}
+}
+
+var _ resource.ConfigValidator = bootOrderValidator{}
+
+// bootOrderValidator checks that the devices in the boot_order of a VM are configured, PVE takes
+// any device name but a VM with missing devices won't boot from them. Clones get the devices of their
+// template, which are left to PVE.
+type bootOrderValidator struct{}
+
+func (v bootOrderValidator) Description(_ context.Context) string {
+	return "boot_order can only have devices configured on the VM"
+}
+
+func (v bootOrderValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v bootOrderValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var bootOrder, disks types.List
+	var clone types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("boot_order"), &bootOrder)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("clone"), &clone)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("disks"), &disks)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if bootOrder.IsNull() || bootOrder.IsUnknown() || !clone.IsNull() || disks.IsUnknown() {
+		return
+	}
+
+	var order []types.String
+	resp.Diagnostics.Append(bootOrder.ElementsAs(ctx, &order, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// disks in the disks list go in the slot given by their interface
+	listed := map[string]bool{}
+	for _, d := range disks.Elements() {
+		o, ok := d.(types.Object)
+		if !ok {
+			continue
+		}
+		i, ok := o.Attributes()["interface"].(types.String)
+		if !ok || i.IsUnknown() {
+			return
+		}
+		listed[i.ValueString()] = true
+	}
+
+	for i, name := range order {
+		if name.IsNull() || name.IsUnknown() || !bootDeviceRe.MatchString(name.ValueString()) || listed[name.ValueString()] {
+			continue
+		}
+
+		attrName := name.ValueString()
+		if id, found := strings.CutPrefix(attrName, "net"); found {
+			n, err := strconv.Atoi(id)
+			if err != nil {
+				continue
+			}
+			attrName = netAttributeName(n)
+		}
+
+		var device types.Object
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(attrName), &device)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if device.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("boot_order").AtListIndex(i),
+				"Invalid Boot Order",
+				fmt.Sprintf("boot_order has %s which isn't configured on the VM.", name.ValueString()),
+			)
+		}
+	}
+}
+
+func BootOrderValidator() resource.ConfigValidator {
+	return bootOrderValidator{}
+}
+
+var _ resource.ConfigValidator = cloudinitStorageValidator{}
+
+// cloudinitStorageValidator checks that IDE drives with cloudinit media have the storage to create the
+// cloud-init drive on, there's no default storage for it.
+type cloudinitStorageValidator struct{}
+
+func (v cloudinitStorageValidator) Description(_ context.Context) string {
+	return "ide drives with cloudinit media require a storage"
+}
+
+func (v cloudinitStorageValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v cloudinitStorageValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	for _, name := range []string{"ide0", "ide1", "ide2", "ide3"} {
+		var media, storage types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(name).AtName("media"), &media)...)
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(name).AtName("storage"), &storage)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if media.ValueString() == mediaCloudinit && storage.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(name).AtName("storage"),
+				"Missing Cloud-Init Storage",
+				fmt.Sprintf("%s with %s media requires a storage to create the cloud-init drive on.", name, mediaCloudinit),
+			)
+		}
+	}
+}
+
+func CloudinitStorageValidator() resource.ConfigValidator {
+	return cloudinitStorageValidator{}
+}
+
+var _ resource.ConfigValidator = agentInterfaceValidator{}
+
+// agentInterfaceValidator checks that agent_interface points at a network device configured on the VM,
+// addresses are never read for one that isn't. Clones get the devices of their template, which are left
+// to PVE.
+type agentInterfaceValidator struct{}
+
+func (v agentInterfaceValidator) Description(_ context.Context) string {
+	return "agent_interface must be a network device configured on the VM"
+}
+
+func (v agentInterfaceValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v agentInterfaceValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var agentInterface types.Int64
+	var clone types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("agent_interface"), &agentInterface)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("clone"), &clone)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if agentInterface.IsNull() || agentInterface.IsUnknown() || !clone.IsNull() {
+		return
+	}
+
+	// out of range is reported by the attribute's own validator
+	id := agentInterface.ValueInt64()
+	if id < 0 || id > 7 {
+		return
+	}
+
+	var net types.Object
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(netAttributeName(int(id))), &net)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if net.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("agent_interface"),
+			"Invalid Agent Interface",
+			fmt.Sprintf("agent_interface is %d but %s isn't configured on the VM.", id, netAttributeName(int(id))),
+		)
+	}
+}
+
+func AgentInterfaceValidator() resource.ConfigValidator {
+	return agentInterfaceValidator{}
 }
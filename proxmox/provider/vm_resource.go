@@ -4,21 +4,33 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
+	"net"
+	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/boolvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
@@ -29,21 +41,41 @@ import (
 )
 
 var (
-	_ resource.Resource                = &vmResource{}
-	_ resource.ResourceWithConfigure   = &vmResource{}
-	_ resource.ResourceWithImportState = &vmResource{}
+	_ resource.Resource                     = &vmResource{}
+	_ resource.ResourceWithConfigure        = &vmResource{}
+	_ resource.ResourceWithImportState      = &vmResource{}
+	_ resource.ResourceWithModifyPlan       = &vmResource{}
+	_ resource.ResourceWithConfigValidators = &vmResource{}
 )
 
 const (
+	defaultVMMemory = 16
+
+	agentIPTimeout    = 5 * time.Minute
+	agentPollInterval = 2 * time.Second
+	// memory PVE uses for a VM without memory in its config
+	pveDefaultVMMemory = 512
+
 	vmTypeQemu string = "qemu"
 	vmTypeLxc  string = "lxc"
 
 	stateRunning string = "running"
 	stateStopped string = "stopped"
 
+	stopModeStop     string = "stop"
+	stopModeShutdown string = "shutdown"
+
 	mediaDisk  string = "disk"
 	mediaCdrom string = "cdrom"
 
+	mediaCloudinit string = "cloudinit"
+
+	biosSeabios string = "seabios"
+	biosOVMF    string = "ovmf"
+
+	efiType2m string = "2m"
+	efiType4m string = "4m"
+
 	formatRaw   string = "raw"
 	formatCow   string = "cow"
 	formatQcow  string = "qcow"
@@ -51,6 +83,22 @@ const (
 	formatQcow2 string = "qcow2"
 	formatVmdk  string = "vmdk"
 	formatCloop string = "cloop"
+
+	cacheNone         string = "none"
+	cacheWriteThrough string = "writethrough"
+	cacheWriteBack    string = "writeback"
+	cacheUnsafe       string = "unsafe"
+	cacheDirectSync   string = "directsync"
+
+	aioNative  string = "native"
+	aioThreads string = "threads"
+	aioIOUring string = "io_uring"
+
+	netModelVirtio  string = "virtio"
+	netModelE1000   string = "e1000"
+	netModelE1000e  string = "e1000e"
+	netModelRtl8139 string = "rtl8139"
+	netModelVmxnet3 string = "vmxnet3"
 )
 
 func NewVMResource() resource.Resource {
@@ -58,27 +106,81 @@ func NewVMResource() resource.Resource {
 }
 
 type vmResource struct {
-	client *pveapi.Client
+	client      *pveapi.Client
+	defaultNode string
+	templates   *vmRefCache
 }
 
 type vmResourceModel struct {
-	Node        types.String `tfsdk:"node"`
-	VMID        types.Int64  `tfsdk:"vmid"`
-	Name        types.String `tfsdk:"name"`
-	Description types.String `tfsdk:"description"`
-
-	Status types.String `tfsdk:"status"`
-	Agent  types.Bool   `tfsdk:"agent"`
-
-	Clone types.String `tfsdk:"clone"`
-
-	Sockets types.Int64 `tfsdk:"sockets"`
-	Cores   types.Int64 `tfsdk:"cores"`
-	Memory  types.Int64 `tfsdk:"memory"`
-
-	IPV4Address types.String `tfsdk:"ipv4_address"`
-
-	Net types.Object `tfsdk:"net"`
+	Node            types.String `tfsdk:"node"`
+	IgnoreNodeDrift types.Bool   `tfsdk:"ignore_node_drift"`
+	VMID            types.Int64  `tfsdk:"vmid"`
+	Name            types.String `tfsdk:"name"`
+	Description     types.String `tfsdk:"description"`
+	Tags            types.Set    `tfsdk:"tags"`
+	Pool            types.String `tfsdk:"pool"`
+
+	Status     types.String `tfsdk:"status"`
+	Onboot     types.Bool   `tfsdk:"onboot"`
+	Startup    types.String `tfsdk:"startup"`
+	Protection types.Bool   `tfsdk:"protection"`
+	Agent      types.Bool   `tfsdk:"agent"`
+	WaitForIP  types.Bool   `tfsdk:"wait_for_ip"`
+	StopMode   types.String `tfsdk:"stop_mode"`
+
+	AgentInterface         types.Int64 `tfsdk:"agent_interface"`
+	AgentExcludeInterfaces types.Set   `tfsdk:"agent_exclude_interfaces"`
+
+	Clone         types.String `tfsdk:"clone"`
+	CloneFull     types.Bool   `tfsdk:"clone_full"`
+	CloneStorage  types.String `tfsdk:"clone_storage"`
+	CloneSnapshot types.String `tfsdk:"clone_snapshot"`
+
+	Bios      types.String `tfsdk:"bios"`
+	BootOrder types.List   `tfsdk:"boot_order"`
+	CPU       types.String `tfsdk:"cpu"`
+	Sockets   types.Int64  `tfsdk:"sockets"`
+	Cores     types.Int64  `tfsdk:"cores"`
+	Vcpus     types.Int64  `tfsdk:"vcpus"`
+	Numa      types.Bool   `tfsdk:"numa"`
+	Memory    types.Int64  `tfsdk:"memory"`
+	Balloon   types.Int64  `tfsdk:"balloon"`
+	Shares    types.Int64  `tfsdk:"shares"`
+
+	CIUser     types.String `tfsdk:"ciuser"`
+	CIPassword types.String `tfsdk:"cipassword"`
+	SSHKeys    types.String `tfsdk:"sshkeys"`
+
+	Nameserver   types.String `tfsdk:"nameserver"`
+	Searchdomain types.String `tfsdk:"searchdomain"`
+
+	Ipconfig0 types.String `tfsdk:"ipconfig0"`
+	Ipconfig1 types.String `tfsdk:"ipconfig1"`
+	Ipconfig2 types.String `tfsdk:"ipconfig2"`
+	Ipconfig3 types.String `tfsdk:"ipconfig3"`
+	Ipconfig4 types.String `tfsdk:"ipconfig4"`
+	Ipconfig5 types.String `tfsdk:"ipconfig5"`
+	Ipconfig6 types.String `tfsdk:"ipconfig6"`
+	Ipconfig7 types.String `tfsdk:"ipconfig7"`
+
+	IPV4Address       types.String `tfsdk:"ipv4_address"`
+	IPV6Address       types.String `tfsdk:"ipv6_address"`
+	IPAddresses       types.List   `tfsdk:"ip_addresses"`
+	NetworkInterfaces types.List   `tfsdk:"network_interfaces"`
+
+	GuestHostname types.String `tfsdk:"guest_hostname"`
+	GuestOS       types.String `tfsdk:"guest_os"`
+
+	Net  types.Object `tfsdk:"net"`
+	Net1 types.Object `tfsdk:"net1"`
+	Net2 types.Object `tfsdk:"net2"`
+	Net3 types.Object `tfsdk:"net3"`
+	Net4 types.Object `tfsdk:"net4"`
+	Net5 types.Object `tfsdk:"net5"`
+	Net6 types.Object `tfsdk:"net6"`
+	Net7 types.Object `tfsdk:"net7"`
+
+	MACAddresses types.Map `tfsdk:"mac_addresses"`
 
 	Virtio0  types.Object `tfsdk:"virtio0"`
 	Virtio1  types.Object `tfsdk:"virtio1"`
@@ -97,62 +199,159 @@ type vmResourceModel struct {
 	Virtio14 types.Object `tfsdk:"virtio14"`
 	Virtio15 types.Object `tfsdk:"virtio15"`
 
+	Disks types.List `tfsdk:"disks"`
+
 	Ide0 types.Object `tfsdk:"ide0"`
 	Ide1 types.Object `tfsdk:"ide1"`
 	Ide2 types.Object `tfsdk:"ide2"`
 	Ide3 types.Object `tfsdk:"ide3"`
+
+	EFIDisk types.Object `tfsdk:"efidisk"`
+
+	Timeouts types.Object `tfsdk:"timeouts"`
+}
+
+type vmTimeoutsModel struct {
+	Create types.String `tfsdk:"create"`
+}
+
+func (vmTimeoutsModel) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"create": types.StringType,
+	}
 }
 
 type virtioModel struct {
 	Media types.String `tfsdk:"media"`
 
-	Format  types.String `tfsdk:"format"`
-	Size    types.Int64  `tfsdk:"size"`
-	Storage types.String `tfsdk:"storage"`
+	Format   types.String `tfsdk:"format"`
+	Size     types.String `tfsdk:"size"`
+	Storage  types.String `tfsdk:"storage"`
+	Cache    types.String `tfsdk:"cache"`
+	AIO      types.String `tfsdk:"aio"`
+	Discard  types.Bool   `tfsdk:"discard"`
+	IOThread types.Bool   `tfsdk:"iothread"`
+	Backup   types.Bool   `tfsdk:"backup"`
 }
 
 func (virtioModel) AttributeTypes() map[string]attr.Type {
 	return map[string]attr.Type{
-		"media":   types.StringType,
-		"format":  types.StringType,
-		"size":    types.Int64Type,
-		"storage": types.StringType,
+		"media":    types.StringType,
+		"format":   types.StringType,
+		"size":     types.StringType,
+		"storage":  types.StringType,
+		"cache":    types.StringType,
+		"aio":      types.StringType,
+		"discard":  types.BoolType,
+		"iothread": types.BoolType,
+		"backup":   types.BoolType,
 	}
 }
 
 func (m *virtioModel) readFromAPIConfig(c *pveapi.QemuVirtIOStorage) {
 	m.Media = types.StringValue(mediaDisk)
 	m.Storage = types.StringValue(c.Disk.Storage)
-	m.Size = types.Int64Value(int64(c.Disk.SizeInKibibytes) / (1024 * 1024))
+	m.Size = diskSizeValue(c.Disk.SizeInKibibytes, m.Size)
 	m.Format = types.StringValue(string(c.Disk.Format))
+	if c.Disk.Cache == "" {
+		m.Cache = types.StringNull()
+	} else {
+		m.Cache = types.StringValue(string(c.Disk.Cache))
+	}
+	if c.Disk.AsyncIO == "" {
+		m.AIO = types.StringNull()
+	} else {
+		m.AIO = types.StringValue(string(c.Disk.AsyncIO))
+	}
+	m.Discard = types.BoolValue(c.Disk.Discard)
+	m.IOThread = types.BoolValue(c.Disk.IOThread)
+	m.Backup = types.BoolValue(c.Disk.Backup)
 }
 
 func (m virtioModel) writeToAPIConfig(c *pveapi.QemuVirtIOStorage) {
 	c.Disk = &pveapi.QemuVirtIODisk{
 		Format:          pveapi.QemuDiskFormat(m.Format.ValueString()),
 		Storage:         m.Storage.ValueString(),
-		SizeInKibibytes: pveapi.QemuDiskSize(m.Size.ValueInt64() * 1024 * 1024),
+		SizeInKibibytes: pveapi.QemuDiskSize(diskSizeKiB(m.Size.ValueString())),
+		Cache:           pveapi.QemuDiskCache(m.Cache.ValueString()),
+		AsyncIO:         pveapi.QemuDiskAsyncIO(m.AIO.ValueString()),
+		Discard:         m.Discard.ValueBool(),
+		IOThread:        m.IOThread.ValueBool(),
+		Backup:          m.Backup.ValueBool(),
+	}
+}
+
+// diskAttributeTypes are the attribute types of an entry in the disks list, a virtio disk along with
+// the slot it goes in.
+func diskAttributeTypes() map[string]attr.Type {
+	t := virtioModel{}.AttributeTypes()
+	t["interface"] = types.StringType
+	return t
+}
+
+// diskFromVirtio returns the entry in the disks list for the virtio disk v in slot iface.
+func diskFromVirtio(iface string, v types.Object) (types.Object, diag.Diagnostics) {
+	attrs := map[string]attr.Value{"interface": types.StringValue(iface)}
+	for k, a := range v.Attributes() {
+		attrs[k] = a
+	}
+	return types.ObjectValue(diskAttributeTypes(), attrs)
+}
+
+// virtioFromDisk splits the entry d in the disks list into the slot it goes in and the virtio disk.
+func virtioFromDisk(d types.Object) (string, types.Object, diag.Diagnostics) {
+	attrs := d.Attributes()
+	iface, _ := attrs["interface"].(types.String)
+	v := map[string]attr.Value{}
+	for k := range (virtioModel{}).AttributeTypes() {
+		v[k] = attrs[k]
 	}
+	o, diags := types.ObjectValue(virtioModel{}.AttributeTypes(), v)
+	return iface.ValueString(), o, diags
 }
 
 type ideModel struct {
-	Media types.String `tfsdk:"media"`
-	File  types.String `tfsdk:"file"`
+	Media   types.String `tfsdk:"media"`
+	File    types.String `tfsdk:"file"`
+	Storage types.String `tfsdk:"storage"`
 }
 
 func (ideModel) AttributeTypes() map[string]attr.Type {
 	return map[string]attr.Type{
-		"media": types.StringType,
-		"file":  types.StringType,
+		"media":   types.StringType,
+		"file":    types.StringType,
+		"storage": types.StringType,
 	}
 }
 
 func (m *ideModel) readFromAPIConfig(c *pveapi.QemuIdeStorage) {
+	if c.CloudInit != nil {
+		m.Media = types.StringValue(mediaCloudinit)
+		m.File = types.StringNull()
+		m.Storage = types.StringValue(c.CloudInit.Storage)
+		return
+	}
+
 	m.Media = types.StringValue(mediaCdrom)
-	m.File = types.StringValue(fmt.Sprintf("%s:%s", c.CdRom.Iso.Storage, c.CdRom.Iso.File))
+	m.Storage = types.StringNull()
+	if c.CdRom != nil && c.CdRom.Iso != nil {
+		m.File = types.StringValue(fmt.Sprintf("%s:%s", c.CdRom.Iso.Storage, c.CdRom.Iso.File))
+	} else {
+		m.File = types.StringNull()
+	}
 }
 
 func (m ideModel) writeToAPIConfig(c *pveapi.QemuIdeStorage) {
+	if m.Media.ValueString() == mediaCloudinit {
+		c.CloudInit = &pveapi.QemuCloudInitDisk{
+			Format:  pveapi.QemuDiskFormat(formatRaw),
+			Storage: m.Storage.ValueString(),
+		}
+		return
+	}
+
+	// a cdrom drive without a file is kept as an empty drive, i.e. the ISO is ejected
+	c.CdRom = &pveapi.QemuCdRom{}
 	parts := strings.Split(m.File.ValueString(), ":")
 	if len(parts) > 1 {
 		re := regexp.MustCompile(`^iso/(.*)$`)
@@ -171,9 +370,14 @@ func (m ideModel) writeToAPIConfig(c *pveapi.QemuIdeStorage) {
 }
 
 type vmNetModel struct {
-	Model      types.String `tfsdk:"model"`
-	Bridge     types.String `tfsdk:"bridge"`
-	MACAddress types.String `tfsdk:"mac_address"`
+	Model      types.String  `tfsdk:"model"`
+	Bridge     types.String  `tfsdk:"bridge"`
+	MACAddress types.String  `tfsdk:"mac_address"`
+	Tag        types.Int64   `tfsdk:"tag"`
+	Firewall   types.Bool    `tfsdk:"firewall"`
+	Rate       types.Float64 `tfsdk:"rate"`
+	Queues     types.Int64   `tfsdk:"queues"`
+	LinkDown   types.Bool    `tfsdk:"link_down"`
 }
 
 func (vmNetModel) AttributeTypes() map[string]attr.Type {
@@ -181,6 +385,11 @@ func (vmNetModel) AttributeTypes() map[string]attr.Type {
 		"model":       types.StringType,
 		"bridge":      types.StringType,
 		"mac_address": types.StringType,
+		"tag":         types.Int64Type,
+		"firewall":    types.BoolType,
+		"rate":        types.Float64Type,
+		"queues":      types.Int64Type,
+		"link_down":   types.BoolType,
 	}
 }
 
@@ -194,6 +403,33 @@ func (m *vmNetModel) readFromAPIConfig(c *pveapi.QemuDevice) {
 	if val, ok := (*c)["macaddr"]; ok {
 		m.MACAddress = types.StringValue(val.(string))
 	}
+	if val, ok := (*c)["tag"].(int); ok {
+		m.Tag = types.Int64Value(int64(val))
+	} else {
+		m.Tag = types.Int64Null()
+	}
+	firewall, _ := (*c)["firewall"].(bool)
+	m.Firewall = types.BoolValue(firewall)
+	// the API client parses options into ints where possible, so a whole number rate isn't a string
+	switch val := (*c)["rate"].(type) {
+	case int:
+		m.Rate = types.Float64Value(float64(val))
+	case string:
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			m.Rate = types.Float64Value(f)
+		} else {
+			m.Rate = types.Float64Null()
+		}
+	default:
+		m.Rate = types.Float64Null()
+	}
+	if val, ok := (*c)["queues"].(int); ok {
+		m.Queues = types.Int64Value(int64(val))
+	} else {
+		m.Queues = types.Int64Null()
+	}
+	linkDown, _ := (*c)["link_down"].(bool)
+	m.LinkDown = types.BoolValue(linkDown)
 }
 
 func (m vmNetModel) writeToAPIConfig(c *pveapi.QemuDevice) {
@@ -204,6 +440,54 @@ func (m vmNetModel) writeToAPIConfig(c *pveapi.QemuDevice) {
 	if !m.MACAddress.IsUnknown() {
 		(*c)["macaddr"] = m.MACAddress.ValueString()
 	}
+	// zero values aren't sent by the API client but keep the current values from being merged back in
+	(*c)["tag"] = int(m.Tag.ValueInt64())
+	(*c)["firewall"] = m.Firewall.ValueBool()
+	(*c)["rate"] = m.Rate.ValueFloat64()
+	(*c)["queues"] = int(m.Queues.ValueInt64())
+	(*c)["link_down"] = m.LinkDown.ValueBool()
+}
+
+type efiDiskModel struct {
+	Storage         types.String `tfsdk:"storage"`
+	Format          types.String `tfsdk:"format"`
+	EFIType         types.String `tfsdk:"efitype"`
+	PreEnrolledKeys types.Bool   `tfsdk:"pre_enrolled_keys"`
+}
+
+func (efiDiskModel) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"storage":           types.StringType,
+		"format":            types.StringType,
+		"efitype":           types.StringType,
+		"pre_enrolled_keys": types.BoolType,
+	}
+}
+
+func (m *efiDiskModel) readFromAPIConfig(c *pveapi.QemuDevice) {
+	if val, ok := (*c)["storage"].(string); ok {
+		m.Storage = types.StringValue(val)
+	}
+	// format is only in the config for some storages, otherwise whatever was given is kept
+	if val, ok := (*c)["format"].(string); ok {
+		m.Format = types.StringValue(val)
+	}
+	if val, ok := (*c)["efitype"].(string); ok {
+		m.EFIType = types.StringValue(val)
+	} else {
+		m.EFIType = types.StringValue(efiType2m)
+	}
+	keys, _ := (*c)["pre-enrolled-keys"].(int)
+	m.PreEnrolledKeys = types.BoolValue(keys == 1)
+}
+
+func (m efiDiskModel) writeToAPIConfig(c *pveapi.QemuDevice) {
+	(*c)["storage"] = m.Storage.ValueString()
+	if !m.Format.IsNull() && !m.Format.IsUnknown() {
+		(*c)["format"] = m.Format.ValueString()
+	}
+	(*c)["efitype"] = m.EFIType.ValueString()
+	(*c)["pre-enrolled-keys"] = m.PreEnrolledKeys.ValueBool()
 }
 
 type VMStateMask uint8
@@ -225,11 +509,21 @@ func (*vmResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 		Description: "This resource manages a Proxmox VM.",
 		Attributes: map[string]schema.Attribute{
 			"node": schema.StringAttribute{
-				Description: "The cluster node name.",
-				Required:    true,
+				Description: "The cluster node name. Defaults to default_node of the provider.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					IgnoreNodeDrift(),
+				},
+			},
+			"ignore_node_drift": schema.BoolAttribute{
+				Description: "Don't try to move the guest back when it's found on another node than configured, e.g. after being migrated by HA. Changes to node are ignored altogether while set.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
 			},
 			"vmid": schema.Int64Attribute{
-				Description: "The (unique) ID of the VM.",
+				Description: "The (unique) ID of the VM. If left out the next free ID is used, should another run take it first a new one is picked, up to 10 attempts. An ID that's left out, or removed from config, is kept only for as long as the VM exists: if it's recreated, e.g. after being destroyed outside of Terraform, it gets the next free ID. Set it to keep the ID stable.",
 				Computed:    true,
 				Optional:    true,
 				PlanModifiers: []planmodifier.Int64{
@@ -237,15 +531,35 @@ func (*vmResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 				},
 			},
 			"name": schema.StringAttribute{
-				Description: "Set a name for the VM. Only used on the configuration web interface.",
+				Description: "Set a name for the VM. Only used on the configuration web interface. Renaming is done in place, removing it from config keeps the current name (e.g. the one given when cloning).",
 				Optional:    true,
 				Computed:    true,
+				Validators: []validator.String{
+					DNSNameValidator("value must be a valid DNS name, i.e. dot separated labels of letters, digits and hyphens, each 1-63 characters and not starting or ending with a hyphen"),
+				},
 			},
 			"description": schema.StringAttribute{
 				Description: "Description for the VM. Shown in the web-interface VM's summary. This is saved as comment inside the configuration file.",
 				Optional:    true,
 				Computed:    true,
 			},
+			"tags": schema.SetAttribute{
+				Description: "Tags of the VM, e.g. [\"prod\", \"web\"]. Tags are only meta information, they can be changed without touching the guest.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Set{
+					RemoveSetUnlessCloned(),
+				},
+				Validators: TagsValidators(),
+			},
+			"pool": schema.StringAttribute{
+				Description: "Resource pool to put the VM in, the pool must already exist. Changing the pool moves the VM to the new pool.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
 			"status": schema.StringAttribute{
 				Description: "QEMU process status.",
 				Optional:    true,
@@ -255,17 +569,110 @@ func (*vmResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 					stringvalidator.OneOf([]string{stateStopped, stateRunning}...),
 				},
 			},
+			"onboot": schema.BoolAttribute{
+				Description: "Specifies whether the VM will be started during system bootup.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"startup": schema.StringAttribute{
+				Description: "Startup and shutdown behavior, e.g. \"order=2,up=30,down=60\". Order is a non-negative number defining the general startup order, shutdown is done in reverse order. Up and down are startup and shutdown delays in seconds.",
+				Optional:    true,
+				Computed:    true,
+				Validators: []validator.String{
+					StartupValidator("value must be a comma separated list of order=<n>, up=<seconds> and down=<seconds> options"),
+				},
+				PlanModifiers: []planmodifier.String{
+					RemoveStringUnlessCloned(),
+				},
+			},
+			"protection": schema.BoolAttribute{
+				Description: "Sets the protection flag of the VM, which prevents the VM and its disks from being removed. The VM can't be destroyed until protection is turned off again.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
 			"agent": schema.BoolAttribute{
 				Description: "Enable/disable communication with the QEMU Guest Agent and its properties.",
 				Optional:    true,
 				Computed:    true,
 				Default:     booldefault.StaticBool(false),
 			},
+			"wait_for_ip": schema.BoolAttribute{
+				Description: "Wait (up to 5 minutes) for the QEMU Guest Agent to report an IPv4 or IPv6 address for the agent_interface device when reading the VM. Reading stops at the first address reported, so on a dual-stack guest ipv4_address may still be empty if its IPv6 address came up first. If false the agent is asked once and the addresses are left empty if it has nothing to report.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"agent_interface": schema.Int64Attribute{
+				Description: "Which network device, 0 for net up to 7 for net7, ipv4_address, ipv6_address and ip_addresses are read for. The addresses are those the QEMU Guest Agent reports for the interface with the MAC address of the device.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(0),
+				Validators: []validator.Int64{
+					int64validator.Between(0, 7),
+				},
+			},
+			"agent_exclude_interfaces": schema.SetAttribute{
+				Description: "Names of network interfaces reported by the QEMU Guest Agent to leave out of network_interfaces and the addresses of the VM, a trailing * matches any suffix. Defaults to loopback and the virtual interfaces of e.g. Docker and Kubernetes, set to [] to keep them all.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				Default:     setdefault.StaticValue(defaultAgentExcludeInterfaces),
+			},
+			"stop_mode": schema.StringAttribute{
+				Description: "How the VM is stopped when status changes to stopped and before it's destroyed. \"stop\" stops it right away, \"shutdown\" asks the guest OS to shut down (ACPI or the QEMU Guest Agent) and waits up to the provider timeout before stopping it.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(stopModeStop),
+				Validators: []validator.String{
+					stringvalidator.OneOf([]string{stopModeStop, stopModeShutdown}...),
+				},
+			},
+			"bios": schema.StringAttribute{
+				Description: "Select BIOS implementation, ovmf (UEFI) requires an efidisk.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(biosSeabios),
+				PlanModifiers: []planmodifier.String{
+					KeepStringIfCloned(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOf([]string{biosSeabios, biosOVMF}...),
+				},
+			},
+			"boot_order": schema.ListAttribute{
+				Description: "Devices to try booting from, in order, e.g. [\"net0\", \"virtio0\"]. Each device has to be configured on the VM.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.List{
+					RemoveListUnlessCloned(),
+				},
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.UniqueValues(),
+					listvalidator.ValueStringsAre(
+						stringvalidator.RegexMatches(bootDeviceRe, "must be a disk or network device like virtio0, ide2 or net0"),
+					),
+				},
+			},
+			"cpu": schema.StringAttribute{
+				Description: "Emulated CPU type, e.g. \"host\", \"x86-64-v2-AES\" or \"kvm64\". Optional flags can be appended, e.g. \"kvm64,flags=+aes\". When not set PVE uses its default (kvm64).",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					RemoveStringUnlessCloned(),
+				},
+			},
 			"sockets": schema.Int64Attribute{
 				Description: "The number of CPU sockets.",
 				Optional:    true,
 				Computed:    true,
 				Default:     int64default.StaticInt64(1),
+				PlanModifiers: []planmodifier.Int64{
+					KeepInt64IfCloned(),
+				},
 				Validators: []validator.Int64{
 					int64validator.AtLeast(1),
 				},
@@ -275,25 +682,175 @@ func (*vmResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 				Optional:    true,
 				Computed:    true,
 				Default:     int64default.StaticInt64(1),
+				PlanModifiers: []planmodifier.Int64{
+					KeepInt64IfCloned(),
+				},
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"vcpus": schema.Int64Attribute{
+				Description: "Number of hotplugged vcpus, can't exceed sockets * cores. When not set all of them are plugged.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Int64{
+					RemoveInt64UnlessCloned(),
+				},
 				Validators: []validator.Int64{
 					int64validator.AtLeast(1),
 				},
 			},
+			"numa": schema.BoolAttribute{
+				Description: "Enable/disable NUMA.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					KeepBoolIfCloned(),
+				},
+			},
 			"memory": schema.Int64Attribute{
 				Description: "Memory in MB",
 				Optional:    true,
 				Computed:    true,
-				Default:     int64default.StaticInt64(16),
+				Default:     int64default.StaticInt64(defaultVMMemory),
+				PlanModifiers: []planmodifier.Int64{
+					KeepInt64IfCloned(),
+				},
+			},
+			"balloon": schema.Int64Attribute{
+				Description: "Amount of target RAM for the VM in MB, can't exceed memory. Using 0 disables the balloon driver. When not set ballooning is enabled with memory as the minimum.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Int64{
+					RemoveInt64UnlessCloned(),
+				},
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"shares": schema.Int64Attribute{
+				Description: "Amount of memory shares for auto-ballooning. The larger the number is, the more memory this VM gets. Using 0 disables auto-ballooning. When not set PVE uses 1000.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Int64{
+					RemoveInt64UnlessCloned(),
+				},
+				Validators: []validator.Int64{
+					int64validator.Between(0, 50000),
+				},
+			},
+			"ciuser": schema.StringAttribute{
+				Description: "cloud-init: User name to change ssh keys and password for instead of the image's configured default user.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					RemoveStringUnlessCloned(),
+				},
+			},
+			"cipassword": schema.StringAttribute{
+				Description: "cloud-init: Password to assign the user. Proxmox never returns it, so changes made outside of Terraform aren't detected.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"sshkeys": schema.StringAttribute{
+				Description: "cloud-init: Setup public SSH keys (one key per line, OpenSSH format).",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					RemoveStringUnlessCloned(),
+				},
+			},
+			"nameserver": schema.StringAttribute{
+				Description: "cloud-init: Sets DNS server IP address for the guest. Create will automatically use the setting from the host if neither searchdomain nor nameserver are set.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					RemoveStringUnlessCloned(),
+				},
+			},
+			"searchdomain": schema.StringAttribute{
+				Description: "cloud-init: Sets DNS search domains for the guest. Create will automatically use the setting from the host if neither searchdomain nor nameserver are set.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					RemoveStringUnlessCloned(),
+				},
 			},
 			"clone": schema.StringAttribute{
-				Description: "Create a full clone of virtual machine/template with this name or VMID.",
+				Description: "Clone the virtual machine/template with this name or VMID. A linked clone is made unless clone_full or clone_storage is set.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIfConfigured(),
+				},
+			},
+			"clone_full": schema.BoolAttribute{
+				Description: "Make a full clone, with disks of its own, instead of a linked clone sharing the disks of the template. Linked clones can only be made from templates. If unset a template gets a linked clone, as before this attribute existed, while a regular VM, which could not be cloned before, or a clone with clone_storage or clone_snapshot set is cloned in full.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplaceIfConfigured(),
+				},
+				Validators: []validator.Bool{
+					boolvalidator.AlsoRequires(path.MatchRoot("clone")),
+				},
+			},
+			"clone_storage": schema.StringAttribute{
+				Description: "Make a full clone with the disks on this storage, instead of a linked clone sharing the disks of the template. Needed when the template's storage can't be used by the new VM, e.g. when it's local to another node.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIfConfigured(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.AlsoRequires(path.MatchRoot("clone")),
+				},
+			},
+
+			"clone_snapshot": schema.StringAttribute{
+				Description: "Clone the state of the source VM at this snapshot, rather than its current state. Requires a full clone.",
 				Optional:    true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplaceIfConfigured(),
 				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.AlsoRequires(path.MatchRoot("clone")),
+				},
+			},
+
+			"timeouts": schema.SingleNestedAttribute{
+				Description: "Timeouts for long running operations.",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"create": schema.StringAttribute{
+						Description: "How long to wait for the VM to be created, e.g. \"30m\". Mostly relevant when cloning large templates. Defaults to the provider timeout.",
+						Optional:    true,
+						Validators: []validator.String{
+							DurationValidator("value must be a duration like 90s, 10m or 1h"),
+						},
+					},
+				},
 			},
 
-			"net": schemaVMNet(),
+			"ipconfig0": schemaIpconfig(),
+			"ipconfig1": schemaIpconfig(),
+			"ipconfig2": schemaIpconfig(),
+			"ipconfig3": schemaIpconfig(),
+			"ipconfig4": schemaIpconfig(),
+			"ipconfig5": schemaIpconfig(),
+			"ipconfig6": schemaIpconfig(),
+			"ipconfig7": schemaIpconfig(),
+
+			"net":  schemaVMNet(),
+			"net1": schemaVMNet(),
+			"net2": schemaVMNet(),
+			"net3": schemaVMNet(),
+			"net4": schemaVMNet(),
+			"net5": schemaVMNet(),
+			"net6": schemaVMNet(),
+			"net7": schemaVMNet(),
+
+			"disks": schemaDisks(),
 
 			"virtio0":  schemaVirtio(),
 			"virtio1":  schemaVirtio(),
@@ -317,66 +874,251 @@ func (*vmResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *res
 			"ide2": schemaIde(),
 			"ide3": schemaIde(),
 
+			"efidisk": schemaEFIDisk(),
+
+			"mac_addresses": schema.MapAttribute{
+				Description: "MAC addresses of all network devices keyed by their PVE name, e.g. {net0 = \"BC:24:11:2E:C5:4A\"}, for e.g. DHCP reservations.",
+				ElementType: types.StringType,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Map{
+					UseStateForUnknownUnlessNetsChange(),
+				},
+			},
 			"ipv4_address": schema.StringAttribute{
-				Description: "Assigned/resolved IPv4 address of the VM.",
+				Description: "Assigned/resolved IPv4 address of the VM. This is the first IPv4 address found in ip_addresses.",
 				Computed:    true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-		},
-	}
-}
-
-func schemaVirtio() schema.Attribute {
-	return schema.SingleNestedAttribute{
-		Description: "Use volume as VIRTIO hard disk.",
-		Optional:    true,
-		Attributes: map[string]schema.Attribute{
-			"media": schema.StringAttribute{
-				Description: "The type of media for this volume (disk or cdrom).",
-				Required:    true,
-				Validators: []validator.String{
-					stringvalidator.OneOf([]string{mediaDisk, mediaCdrom}...),
+			"ipv6_address": schema.StringAttribute{
+				Description: "Assigned/resolved IPv6 address of the VM. This is the first IPv6 address found in ip_addresses.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"format": schema.StringAttribute{
-				Description: "Format identifier (raw, cow, qcow, qed, qcow2, vmdk, cloop).",
-				Optional:    true,
+			"ip_addresses": schema.ListAttribute{
+				Description: "All global unicast addresses, IPv4 and IPv6, reported by the guest agent for the VM's network device picked by agent_interface.",
+				ElementType: types.StringType,
 				Computed:    true,
-				Default:     stringdefault.StaticString(formatRaw),
-				Validators: []validator.String{
-					stringvalidator.OneOf([]string{formatRaw, formatCow, formatQcow, formatQed, formatQcow2, formatVmdk, formatCloop}...),
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"size": schema.Int64Attribute{
-				Description: "Volume size in GB.",
-				Optional:    true,
+			"network_interfaces": schema.ListNestedAttribute{
+				Description: "All network interfaces reported by the guest agent, including e.g. loopback and interfaces of containers running in the guest, null if the agent isn't available.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "Name of the interface in the guest, e.g. eth0.",
+							Computed:    true,
+						},
+						"mac_address": schema.StringAttribute{
+							Description: "MAC address of the interface.",
+							Computed:    true,
+						},
+						"ip_addresses": schema.ListAttribute{
+							Description: "All addresses of the interface, IPv4 and IPv6.",
+							ElementType: types.StringType,
+							Computed:    true,
+						},
+					},
+				},
 			},
-			"storage": schema.StringAttribute{
-				Description: "The storage identifier.",
-				Optional:    true,
+			"guest_hostname": schema.StringAttribute{
+				Description: "Host name as reported by the guest agent, null if the agent isn't available.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"guest_os": schema.StringAttribute{
+				Description: "Name of the guest's operating system as reported by the guest agent, null if the agent isn't available.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
 }
 
-func schemaIde() schema.Attribute {
+func schemaIpconfig() schema.Attribute {
+	return schema.StringAttribute{
+		Description: "cloud-init: Specify IP addresses and gateways for the corresponding interface, e.g. \"ip=10.0.0.5/24,gw=10.0.0.1\", \"ip=dhcp\" or \"ip6=2001:db8::5/64,gw6=2001:db8::1\".",
+		Optional:    true,
+		Validators: []validator.String{
+			IpconfigValidator("value must be a comma separated list of ip=<IPv4 CIDR|dhcp>, gw=<IPv4>, ip6=<IPv6 CIDR|dhcp|auto> and gw6=<IPv6> options"),
+		},
+	}
+}
+
+func schemaVirtio() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Description: "Use volume as VIRTIO hard disk.",
+		Optional:    true,
+		Attributes:  virtioAttributes(),
+	}
+}
+
+func schemaDisks() schema.Attribute {
+	attrs := virtioAttributes()
+	attrs["interface"] = schema.StringAttribute{
+		Description: "The slot the disk goes in, virtio0 to virtio15.",
+		Required:    true,
+		Validators: []validator.String{
+			stringvalidator.RegexMatches(diskInterfaceRe, "interface must be virtio0 to virtio15"),
+		},
+	}
+
+	conflicts := make([]path.Expression, 0, 16)
+	for i := 0; i < 16; i++ {
+		conflicts = append(conflicts, path.MatchRoot(fmt.Sprintf("virtio%d", i)))
+	}
+
+	return schema.ListNestedAttribute{
+		Description: "VIRTIO hard disks as a list, an alternative to the virtio0 to virtio15 attributes which can't be combined with it.",
+		Optional:    true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: attrs,
+		},
+		Validators: []validator.List{
+			listvalidator.ConflictsWith(conflicts...),
+		},
+	}
+}
+
+func virtioAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"media": schema.StringAttribute{
+			Description: "The type of media for this volume (disk or cdrom).",
+			Required:    true,
+			Validators: []validator.String{
+				stringvalidator.OneOf([]string{mediaDisk, mediaCdrom}...),
+			},
+		},
+		"format": schema.StringAttribute{
+			Description: "Format identifier (raw, cow, qcow, qed, qcow2, vmdk, cloop).",
+			Optional:    true,
+			Computed:    true,
+			Default:     stringdefault.StaticString(formatRaw),
+			Validators: []validator.String{
+				stringvalidator.OneOf([]string{formatRaw, formatCow, formatQcow, formatQed, formatQcow2, formatVmdk, formatCloop}...),
+			},
+		},
+		"size": schema.StringAttribute{
+			Description: "Volume size, in KB if ending in K, in MB if ending in M or else in GB, e.g. \"512M\" or \"30G\". A bare number is read as GB.",
+			Optional:    true,
+			Validators: []validator.String{
+				DiskSizeValidator("size must be numbers only, possibly ending in K, M or G"),
+			},
+		},
+		"storage": schema.StringAttribute{
+			Description: "The storage identifier.",
+			Optional:    true,
+		},
+		"cache": schema.StringAttribute{
+			Description: "The drive's cache mode (none, writethrough, writeback, unsafe, directsync). Leave unset to use the Proxmox default.",
+			Optional:    true,
+			Validators: []validator.String{
+				stringvalidator.OneOf([]string{cacheNone, cacheWriteThrough, cacheWriteBack, cacheUnsafe, cacheDirectSync}...),
+			},
+		},
+		"aio": schema.StringAttribute{
+			Description: "The drive's async IO mode (native, threads, io_uring). Leave unset to use the Proxmox default. Some storages need native together with cache none.",
+			Optional:    true,
+			Validators: []validator.String{
+				stringvalidator.OneOf([]string{aioNative, aioThreads, aioIOUring}...),
+			},
+		},
+		"discard": schema.BoolAttribute{
+			Description: "Pass discard/trim requests to the underlying storage, lets the guest free up space on thin-provisioned storage.",
+			Optional:    true,
+			Computed:    true,
+			Default:     booldefault.StaticBool(false),
+		},
+		"iothread": schema.BoolAttribute{
+			Description: "Run IO for this drive in its own thread, can improve performance for IO heavy VMs.",
+			Optional:    true,
+			Computed:    true,
+			Default:     booldefault.StaticBool(false),
+		},
+		"backup": schema.BoolAttribute{
+			Description: "Include the drive in backups, set to false for e.g. scratch disks.",
+			Optional:    true,
+			Computed:    true,
+			Default:     booldefault.StaticBool(true),
+		},
+	}
+}
+
+func schemaIde() schema.Attribute {
 	return schema.SingleNestedAttribute{
 		Description: "Use volume as IDE hard disk.",
 		Optional:    true,
 		Attributes: map[string]schema.Attribute{
 			"media": schema.StringAttribute{
-				Description: "The type of media for this volume (currently only cdrom supported).",
+				Description: "The type of media for this volume (cdrom or cloudinit).",
 				Required:    true,
 				Validators: []validator.String{
-					stringvalidator.OneOf([]string{mediaCdrom}...),
+					stringvalidator.OneOf([]string{mediaCdrom, mediaCloudinit}...),
 				},
 			},
 			"file": schema.StringAttribute{
-				Description: "ISO identifier",
+				Description: "ISO identifier, for cdrom media. Leaving it out gives an empty drive, removing it ejects the ISO.",
+				Optional:    true,
+			},
+			"storage": schema.StringAttribute{
+				Description: "The storage to create the cloud-init drive on, required for cloudinit media.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("file")),
+				},
+			},
+		},
+	}
+}
+
+func schemaEFIDisk() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Description: "Disk for storing EFI vars, needed when bios is ovmf. It can't be changed in place, changing it recreates the VM and removing it from config keeps the current disk.",
+		Optional:    true,
+		Computed:    true,
+		PlanModifiers: []planmodifier.Object{
+			objectplanmodifier.UseStateForUnknown(),
+			objectplanmodifier.RequiresReplace(),
+		},
+		Attributes: map[string]schema.Attribute{
+			"storage": schema.StringAttribute{
+				Description: "The storage to create the disk on.",
+				Required:    true,
+			},
+			"format": schema.StringAttribute{
+				Description: "Format identifier (raw, cow, qcow, qed, qcow2, vmdk, cloop). Not all storages support all formats.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf([]string{formatRaw, formatCow, formatQcow, formatQed, formatQcow2, formatVmdk, formatCloop}...),
+				},
+			},
+			"efitype": schema.StringAttribute{
+				Description: "Size and type of the OVMF EFI vars. 4m is newer and recommended, and required for Secure Boot.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(efiType4m),
+				Validators: []validator.String{
+					stringvalidator.OneOf([]string{efiType2m, efiType4m}...),
+				},
+			},
+			"pre_enrolled_keys": schema.BoolAttribute{
+				Description: "Use an EFI vars template with distribution-specific and Microsoft Standard keys enrolled, enabling Secure Boot by default. Requires efitype 4m.",
 				Optional:    true,
 				Computed:    true,
+				Default:     booldefault.StaticBool(false),
 			},
 		},
 	}
@@ -384,15 +1126,18 @@ func schemaIde() schema.Attribute {
 
 func schemaVMNet() schema.Attribute {
 	return schema.SingleNestedAttribute{
-		Description: "Specifies the network device on a VM.",
+		Description: "Specifies a network device on a VM, net is the first device (net0 in PVE).",
 		Optional:    true,
 		Computed:    true,
 		Attributes: map[string]schema.Attribute{
 			"model": schema.StringAttribute{
-				Description: "Network device model.",
-				Required:    false,
+				Description: "Network device model (virtio, e1000, e1000e, rtl8139, vmxnet3).",
+				Optional:    true,
 				Computed:    true,
-				Default:     stringdefault.StaticString("virtio"),
+				Default:     stringdefault.StaticString(netModelVirtio),
+				Validators: []validator.String{
+					stringvalidator.OneOf([]string{netModelVirtio, netModelE1000, netModelE1000e, netModelRtl8139, netModelVmxnet3}...),
+				},
 			},
 			"bridge": schema.StringAttribute{
 				Description: "The interface to bridge this interface to.",
@@ -406,29 +1151,84 @@ func schemaVMNet() schema.Attribute {
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"tag": schema.Int64Attribute{
+				Description: "VLAN tag to apply to packets on this interface.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.Between(1, 4094),
+				},
+			},
+			"firewall": schema.BoolAttribute{
+				Description: "Whether this interface should be protected by the firewall.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"rate": schema.Float64Attribute{
+				Description: "Rate limit in MB/s.",
+				Optional:    true,
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			"queues": schema.Int64Attribute{
+				Description: "Number of packet queues to be used on the device.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.Between(0, 64),
+				},
+			},
+			"link_down": schema.BoolAttribute{
+				Description: "Whether this interface should be disconnected (like pulling the plug).",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
 		},
 		PlanModifiers: []planmodifier.Object{
-			objectplanmodifier.UseStateForUnknown(),
+			RemoveUnlessCloned(),
 		},
 	}
 }
 
+func (*vmResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		BalloonValidator(),
+		CloneFullValidator(),
+		VcpusValidator(),
+		EFIDiskValidator(),
+		BootOrderValidator(),
+		CloudinitStorageValidator(),
+		AgentInterfaceValidator(),
+	}
+}
+
 func (r *vmResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*pveapi.Client)
+	data, ok := req.ProviderData.(*proxmoxProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Provider Data Type",
-			fmt.Sprintf("Expected %T, got: %T. Please report this to the provider developers.", client, req.ProviderData),
+			fmt.Sprintf("Expected %T, got: %T. Please report this to the provider developers.", data, req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = data.client
+	r.defaultNode = data.defaultNode
+	r.templates = data.templates
+}
+
+func (r *vmResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// provider isn't configured yet, e.g. its config depends on values not known until apply
+	if r.client == nil {
+		return
+	}
+	resp.Diagnostics.Append(planDefaultNode(ctx, req, resp, r.defaultNode)...)
 }
 
 func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -439,6 +1239,20 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 		return
 	}
 
+	var vmr *pveapi.VmRef
+	defer func() {
+		vmid := plan.VMID.ValueInt64()
+		if vmr != nil {
+			vmid = int64(vmr.VmId())
+		}
+		resp.Diagnostics = withGuestContext(resp.Diagnostics, "VM", plan.Node.ValueString(), vmid)
+	}()
+
+	resp.Diagnostics.Append(plan.expandDisks(ctx)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	config := &pveapi.ConfigQemu{}
 	err := apiConfigFromVMResourceModel(ctx, &plan, config)
 	if err != nil {
@@ -449,9 +1263,28 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 	}
 	tflog.Trace(ctx, fmt.Sprintf("Creating VM from model: %+v", plan))
 
-	var vmr *pveapi.VmRef
+	resp.Diagnostics.Append(checkNode(r.client, plan.Node.ValueString(), "Error Creating VM")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(checkNetBridges(r.client, plan.Node.ValueString(), config.QemuNetworks, "Error Creating VM")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.CloneStorage.IsNull() {
+		resp.Diagnostics.Append(checkDiskStorage(r.client, plan.Node.ValueString(), plan.CloneStorage.ValueString(), path.Root("clone_storage"), "Error Creating VM")...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	refreshedCloneSource := false
 
-	// run in a loop so we can retry if ID collision, not beautiful
+	// run in a loop so we can retry if ID collision, not beautiful. Collisions are counted on their
+	// own since a retry with a fresh lookup of the template doesn't count towards maxIDAttempts.
+	collisions := 0
 	for {
 		id, err := getIDToUse(plan.VMID, r.client)
 		if err != nil {
@@ -467,10 +1300,22 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 		if plan.Clone.IsNull() {
 			err = config.Create(vmr, r.client)
 			if err != nil {
-				re := regexp.MustCompile(`500 unable to create VM \d+ \- VM \d+ already exists`)
-				if plan.VMID.IsUnknown() && re.MatchString(err.Error()) {
-					// if we tried creating with an auto-assigned ID try again
-					continue
+				if vmIDTakenRe.MatchString(err.Error()) {
+					collisions++
+					if plan.VMID.IsUnknown() && collisions < maxIDAttempts {
+						// if we tried creating with an auto-assigned ID try again
+						tflog.Trace(ctx, fmt.Sprintf("VMID %d was taken, retrying with a new one", id))
+						if err := waitIDCollisionBackoff(ctx, collisions); err != nil {
+							resp.Diagnostics.AddError(
+								"Error Creating VM",
+								"Interrupted while waiting to retry with a new VM ID: "+err.Error(),
+							)
+							return
+						}
+						continue
+					}
+					resp.Diagnostics.Append(vmIDTakenError(id, plan.VMID, err)...)
+					return
 				}
 
 				resp.Diagnostics.AddError(
@@ -483,16 +1328,19 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 			tflog.Trace(ctx, "Created VM")
 		} else {
 			fullClone := new(int)
-			*fullClone = 0
+			if plan.CloneFull.ValueBool() || (plan.CloneFull.IsNull() && (!plan.CloneStorage.IsNull() || !plan.CloneSnapshot.IsNull())) {
+				*fullClone = 1
+			}
 			config.FullClone = fullClone
 
 			var srcvmr *pveapi.VmRef
+			srcFromCache := false
 			if cloneID, err := strconv.ParseInt(plan.Clone.ValueString(), 10, 64); err == nil {
 				srcvmr = pveapi.NewVmRef(int(cloneID))
 				// I think its possible the clone template is not on the same node?
 				srcvmr.SetNode(plan.Node.ValueString())
 			} else {
-				srcvmr, err = r.client.GetVmRefByName(plan.Clone.ValueString())
+				srcvmr, srcFromCache, err = r.templates.resolve(r.client, plan.Clone.ValueString())
 				if err != nil {
 					resp.Diagnostics.AddError(
 						"Error Creating VM",
@@ -502,14 +1350,92 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 				}
 			}
 
-			err = config.CloneVm(srcvmr, vmr, r.client)
+			if *fullClone == 0 {
+				template, diags := cloneSourceIsTemplate(r.client, srcvmr, plan.Clone.ValueString())
+				resp.Diagnostics.Append(diags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				if !template && plan.CloneFull.IsNull() {
+					// linked clones can only be made of templates, so a regular VM is always cloned in full
+					tflog.Trace(ctx, fmt.Sprintf("'%s' is not a template, making a full clone", plan.Clone.ValueString()))
+					*fullClone = 1
+				} else if !template {
+					resp.Diagnostics.AddAttributeError(
+						path.Root("clone"),
+						"Error Creating VM",
+						fmt.Sprintf("'%s' is not a template, linked clones can only be made from templates. Set clone_full = true, or leave it unset, to make a full clone instead.", plan.Clone.ValueString()),
+					)
+					return
+				}
+			}
+			if !plan.CloneSnapshot.IsNull() {
+				resp.Diagnostics.Append(checkCloneSnapshot(r.client, srcvmr, plan.Clone.ValueString(), plan.CloneSnapshot.ValueString())...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+			}
+
+			// the clone is grown to the planned disk sizes when updating it below, but a disk that's planned
+			// smaller than the template's is only known to fail after cloning, so check that first
+			templateConfig, err := pveapi.NewConfigQemuFromApi(srcvmr, r.client)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Creating VM",
+					fmt.Sprintf("Could not read config of '%s' to clone, unexpected error: %s", plan.Clone.ValueString(), err.Error()),
+				)
+				return
+			}
+			resp.Diagnostics.Append(checkCloneDiskSizes(ctx, &plan, templateConfig)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			timeout, err := createTimeout(ctx, plan.Timeouts, r.client)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Creating VM",
+					"Could not read create timeout, unexpected error: "+err.Error(),
+				)
+				return
+			}
+
+			err = cloneVM(ctx, r.client, config, srcvmr, vmr, plan.CloneStorage.ValueString(), plan.CloneSnapshot.ValueString(), plan.Pool.ValueString(), timeout)
 			if err != nil {
-				re := regexp.MustCompile(`unable to create VM \d+: config file already exists`)
-				if plan.VMID.IsUnknown() && re.MatchString(err.Error()) {
-					// if we tried cloning with an auto-assigned ID try again
+				if vmIDTakenRe.MatchString(err.Error()) {
+					collisions++
+					if plan.VMID.IsUnknown() && collisions < maxIDAttempts {
+						// if we tried cloning with an auto-assigned ID try again
+						tflog.Trace(ctx, fmt.Sprintf("VMID %d was taken, retrying with a new one", id))
+						if err := waitIDCollisionBackoff(ctx, collisions); err != nil {
+							resp.Diagnostics.AddError(
+								"Error Creating VM",
+								"Interrupted while waiting to retry with a new VM ID: "+err.Error(),
+							)
+							return
+						}
+						continue
+					}
+					// an explicit vmid won't free up by trying again, nor by looking up the template again
+					resp.Diagnostics.Append(vmIDTakenError(id, plan.VMID, err)...)
+					return
+				}
+
+				if r.templates.retryIfStale(plan.Clone.ValueString(), srcFromCache, &refreshedCloneSource) {
+					tflog.Trace(ctx, fmt.Sprintf("Cloning from cached template '%s' failed, retrying with fresh lookup", plan.Clone.ValueString()))
 					continue
 				}
 
+				if *fullClone == 0 && cloneStorageErrorRe.MatchString(err.Error()) {
+					resp.Diagnostics.AddAttributeError(
+						path.Root("clone"),
+						"Error Creating VM",
+						fmt.Sprintf("Could not make a linked clone of '%s' on node %s, the storage of its disks can't be used by the new VM. Set clone_storage to make a full clone onto a storage available on %s instead.\n\n%s", plan.Clone.ValueString(), vmr.Node(), vmr.Node(), err.Error()),
+					)
+					return
+				}
+
 				resp.Diagnostics.AddError(
 					"Error Creating VM",
 					"Could not clone VM, unexpected error: "+err.Error(),
@@ -519,8 +1445,22 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 
 			tflog.Trace(ctx, "Created VM by cloning")
 
-			// would be great if the API client read description from config and sent it along the clone request
-			// .. until then, set it manually
+			// the clone request only takes name, description and pool, set the rest of the config in one
+			// update, keeping whatever we don't manage as it was on the template
+			currentConfig, err := pveapi.NewConfigQemuFromApi(vmr, r.client)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Creating VM",
+					"Could not read VM config after cloning, unexpected error: "+err.Error(),
+				)
+				return
+			}
+			mergeUnmanagedVMConfig(config, currentConfig)
+
+			// the clone was put in its pool right away, don't let the API client move it out of it again
+			vmr.SetPool("")
+
+			vmr.SetVmType(vmTypeQemu)
 			requiresReboot, err := config.Update(false, vmr, r.client)
 			if err != nil {
 				resp.Diagnostics.AddError(
@@ -554,6 +1494,29 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 		break
 	}
 
+	if params := vmExtraParams(&plan); len(params) > 0 {
+		err = r.client.Put(params, fmt.Sprintf("/nodes/%s/qemu/%d/config", vmr.Node(), vmr.VmId()))
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Creating VM",
+				"Could not set options on VM, unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	// clones are put in their pool by the clone request itself
+	if plan.Clone.IsNull() {
+		err = moveGuestToPool(r.client, vmr.VmId(), "", plan.Pool.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Creating VM",
+				"Could not add VM to pool, unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
 	if plan.Status.ValueString() == stateRunning {
 		tflog.Trace(ctx, "Starting VM since status set to "+plan.Status.ValueString())
 		_, err := r.client.StartVm(vmr)
@@ -576,6 +1539,11 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 		return
 	}
 
+	resp.Diagnostics.Append(plan.collapseDisks(ctx)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	tflog.Trace(ctx, fmt.Sprintf("Setting state after creating VM to: %+v", plan))
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
@@ -591,6 +1559,9 @@ func (r *vmResource) Read(ctx context.Context, req resource.ReadRequest, resp *r
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	defer func() {
+		resp.Diagnostics = withGuestContext(resp.Diagnostics, "VM", state.Node.ValueString(), state.VMID.ValueInt64())
+	}()
 
 	if !state.VMID.IsUnknown() {
 		tflog.Trace(ctx, fmt.Sprintf("Reading state for VM %d", state.VMID.ValueInt64()))
@@ -606,7 +1577,7 @@ func (r *vmResource) Read(ctx context.Context, req resource.ReadRequest, resp *r
 
 		vmExists := false
 		for _, vm := range vms {
-			if int64(vm.Id) == state.VMID.ValueInt64() {
+			if int64(vm.Id) == state.VMID.ValueInt64() && vm.Type == pveapi.GuestQemu {
 				vmExists = true
 				break
 			}
@@ -618,6 +1589,11 @@ func (r *vmResource) Read(ctx context.Context, req resource.ReadRequest, resp *r
 			return
 		}
 
+		resp.Diagnostics.Append(state.expandDisks(ctx)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
 		err = UpdateVMResourceModelFromAPI(ctx, int(state.VMID.ValueInt64()), r.client, &state, VMStateEverything)
 		if err != nil {
 			resp.Diagnostics.AddError(
@@ -626,6 +1602,11 @@ func (r *vmResource) Read(ctx context.Context, req resource.ReadRequest, resp *r
 			)
 			return
 		}
+
+		resp.Diagnostics.Append(state.collapseDisks(ctx)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 		tflog.Trace(ctx, fmt.Sprintf("Read state %+v", state))
 	}
 
@@ -644,8 +1625,30 @@ func (r *vmResource) Update(ctx context.Context, req resource.UpdateRequest, res
 		return
 	}
 
+	var prior vmResourceModel
+	diags = req.State.Get(ctx, &prior)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer func() {
+		resp.Diagnostics = withGuestContext(resp.Diagnostics, "VM", plan.Node.ValueString(), prior.VMID.ValueInt64())
+	}()
+
 	tflog.Trace(ctx, fmt.Sprintf("Updating VM with plan: %+v", plan))
 
+	resp.Diagnostics.Append(plan.expandDisks(ctx)...)
+	resp.Diagnostics.Append(prior.expandDisks(ctx)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diskChanges, diags := diffVirtioDisks(ctx, &prior, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	config := &pveapi.ConfigQemu{}
 	err := apiConfigFromVMResourceModel(ctx, &plan, config)
 	if err != nil {
@@ -665,64 +1668,230 @@ func (r *vmResource) Update(ctx context.Context, req resource.UpdateRequest, res
 	vmr := pveapi.NewVmRef(id)
 	vmr.SetNode(plan.Node.ValueString())
 
-	_, err = config.Update(false, vmr, r.client)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating VM",
-			"Could not update VM, unexpected error: "+err.Error(),
-		)
+	resp.Diagnostics.Append(checkNode(r.client, plan.Node.ValueString(), "Error Updating VM")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(checkNetBridges(r.client, plan.Node.ValueString(), config.QemuNetworks, "Error Updating VM")...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	tflog.Trace(ctx, fmt.Sprintf("VM %d updated", id))
 
-	reboot, err := pveapi.GuestHasPendingChanges(vmr, r.client)
+	currentConfig, err := pveapi.NewConfigQemuFromApi(vmr, r.client)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Updating VM",
-			"Unable to determine if VM needs reboot after updating it, unexpected error: "+err.Error(),
+			"Could not read current VM config before updating, unexpected error: "+err.Error(),
 		)
 		return
 	}
-	if reboot {
-		// RebootVm (ie POST ../status/reboot) hangs and never completes, probably because we're testing on VMs with nothing installed
-		tflog.Trace(ctx, fmt.Sprintf("Rebooting VM %d...", id))
-
-		_, err = r.client.StopVm(vmr)
+	mergeUnmanagedVMConfig(config, currentConfig)
+
+	// reading the config looked up the VM's pool, which the API client would then move the VM out of
+	// when updating since config has no pool. Pools are handled separately, see moveGuestToPool.
+	currentPool := currentConfig.Pool
+	vmr.SetPool("")
+
+	vmr.SetVmType(vmTypeQemu)
+	cosmetic := onlyCosmeticVMChanges(&prior, &plan)
+	if cosmetic {
+		// nothing affecting the guest itself changed, so set just those options to make sure the VM is never rebooted for it
+		tflog.Trace(ctx, fmt.Sprintf("Only cosmetic changes to VM %d, setting them directly", id))
+		params, err := cosmeticVMParams(ctx, &prior, &plan)
 		if err != nil {
 			resp.Diagnostics.AddError(
-				"Error Updating VM",
-				"Could not stop VM as part of reboot after updating it, unexpected error: "+err.Error(),
-			)
+				"Error constructing API struct from internal model",
+				"This is a provider bug. Please report it to the developers.\n\n"+err.Error())
 			return
 		}
+		if len(params) > 0 {
+			_, err = r.client.SetVmConfig(vmr, params)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Updating VM",
+					"Could not update VM, unexpected error: "+err.Error(),
+				)
+				return
+			}
+		}
+	} else {
+		// the API client moves disks itself too, but only along with growing them or keeping their size,
+		// which a disk without a configured size doesn't. Growing is left to it.
+		for _, disk := range sortedKeys(diskChanges.moves) {
+			tflog.Trace(ctx, fmt.Sprintf("Moving %s of VM %d to storage %s", disk, id, diskChanges.moves[disk]))
+			err = pveapi.MoveQemuDisk(nil, pveapi.QemuDiskId(disk), diskChanges.moves[disk], true, vmr, r.client)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Updating VM",
+					fmt.Sprintf("Could not move %s to storage '%s', unexpected error: %s", disk, diskChanges.moves[disk], err.Error()),
+				)
+				return
+			}
+		}
 
-		_, err = r.client.StartVm(vmr)
+		_, err = config.Update(false, vmr, r.client)
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Error Updating VM",
-				"Could not start VM as part of reboot after updating it, unexpected error: "+err.Error(),
+				"Could not update VM, unexpected error: "+err.Error(),
 			)
 			return
 		}
-
-		tflog.Trace(ctx, fmt.Sprintf("Rebooted VM %d.", id))
-	}
-
-	var state vmResourceModel
-
-	// carry over .clone since it is merely a property in TF state not backed by anything on the PVE side
-	state.Clone = plan.Clone
-
-	err = UpdateVMResourceModelFromAPI(ctx, id, r.client, &state, VMStateEverything)
-	if err != nil {
-		resp.Diagnostics.AddError(
+		// the API client only ever sets options, those removed from the plan need to be deleted explicitly
+		var deletes []string
+		for i, net := range plan.nets() {
+			if _, ok := currentConfig.QemuNetworks[i]; net.IsNull() && ok {
+				deletes = append(deletes, fmt.Sprintf("net%d", i))
+			}
+		}
+		for i, ipconfig := range plan.ipconfigs() {
+			if ipconfig.IsNull() && currentConfig.Ipconfig[i] != nil {
+				deletes = append(deletes, fmt.Sprintf("ipconfig%d", i))
+			}
+		}
+		if plan.Startup.IsNull() && currentConfig.Startup != "" {
+			deletes = append(deletes, "startup")
+		}
+		// the API client doesn't send an empty description, so description = "" has to remove it
+		if !plan.Description.IsUnknown() && plan.Description.ValueString() == "" && currentConfig.Description != "" {
+			deletes = append(deletes, "description")
+		}
+		// the current config has cpu defaulted by the API client, so go by what was in state
+		if plan.CPU.IsNull() && !prior.CPU.IsNull() {
+			deletes = append(deletes, "cpu")
+		}
+		if plan.Vcpus.IsNull() && currentConfig.QemuVcpus > 0 {
+			deletes = append(deletes, "vcpus")
+		}
+		if plan.Balloon.IsNull() && !prior.Balloon.IsNull() {
+			deletes = append(deletes, "balloon")
+		}
+		if plan.Shares.IsNull() && !prior.Shares.IsNull() {
+			deletes = append(deletes, "shares")
+		}
+		if plan.BootOrder.IsNull() && !prior.BootOrder.IsNull() {
+			deletes = append(deletes, "boot")
+		}
+		if plan.Tags.IsNull() && !prior.Tags.IsNull() {
+			deletes = append(deletes, "tags")
+		}
+		// the API client doesn't send empty cloud-init options either
+		if plan.CIUser.IsNull() && !prior.CIUser.IsNull() {
+			deletes = append(deletes, "ciuser")
+		}
+		if plan.CIPassword.IsNull() && !prior.CIPassword.IsNull() {
+			deletes = append(deletes, "cipassword")
+		}
+		if plan.SSHKeys.IsNull() && !prior.SSHKeys.IsNull() {
+			deletes = append(deletes, "sshkeys")
+		}
+		if plan.Nameserver.IsNull() && !prior.Nameserver.IsNull() {
+			deletes = append(deletes, "nameserver")
+		}
+		if plan.Searchdomain.IsNull() && !prior.Searchdomain.IsNull() {
+			deletes = append(deletes, "searchdomain")
+		}
+		params := vmExtraParams(&plan)
+		if len(deletes) > 0 {
+			tflog.Trace(ctx, fmt.Sprintf("Removing %s from VM %d", strings.Join(deletes, ", "), id))
+			params["delete"] = strings.Join(deletes, ",")
+		}
+		if len(params) > 0 {
+			err = r.client.Put(params, fmt.Sprintf("/nodes/%s/qemu/%d/config", vmr.Node(), id))
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Updating VM",
+					"Could not set or remove options on VM, unexpected error: "+err.Error(),
+				)
+				return
+			}
+		}
+	}
+
+	err = moveGuestToPool(r.client, id, currentPool, plan.Pool.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating VM",
+			"Could not move VM to pool, unexpected error: "+err.Error(),
+		)
+		return
+	}
+	tflog.Trace(ctx, fmt.Sprintf("VM %d updated", id))
+
+	reboot := false
+	if !cosmetic {
+		reboot, err = pveapi.GuestHasPendingChanges(vmr, r.client)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating VM",
+				"Unable to determine if VM needs reboot after updating it, unexpected error: "+err.Error(),
+			)
+			return
+		}
+
+		if reboot {
+			skip, diags := skipRepeatedReboot(ctx, r.client, vmr, vmTypeQemu, "VM", resp.Private)
+			resp.Diagnostics.Append(diags...)
+			reboot = !skip
+		} else {
+			resp.Diagnostics.Append(rememberPendingAfterReboot(ctx, resp.Private, nil)...)
+		}
+	}
+	if reboot {
+		tflog.Trace(ctx, fmt.Sprintf("Rebooting VM %d...", id))
+
+		err = rebootGuest(ctx, r.client, vmr, vmTypeQemu)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating VM",
+				"Could not reboot VM after updating it, unexpected error: "+err.Error(),
+			)
+			return
+		}
+
+		tflog.Trace(ctx, fmt.Sprintf("Rebooted VM %d.", id))
+
+		resp.Diagnostics.Append(checkPendingAfterReboot(ctx, r.client, vmr, vmTypeQemu, "VM", resp.Private)...)
+	}
+
+	var state vmResourceModel
+
+	// carry over .clone, .clone_full, .clone_storage, .clone_snapshot, .wait_for_ip, .stop_mode, .agent_interface, .agent_exclude_interfaces and .timeouts since they are merely properties in TF state not backed by anything on the PVE side
+	// .. and .cipassword since it can't be read back
+	state.Clone = plan.Clone
+	state.CloneFull = plan.CloneFull
+	state.CloneStorage = plan.CloneStorage
+	state.CloneSnapshot = plan.CloneSnapshot
+	state.CIPassword = plan.CIPassword
+	state.WaitForIP = plan.WaitForIP
+	state.StopMode = plan.StopMode
+	state.AgentInterface = plan.AgentInterface
+	state.AgentExcludeInterfaces = plan.AgentExcludeInterfaces
+	state.Timeouts = plan.Timeouts
+	state.IgnoreNodeDrift = plan.IgnoreNodeDrift
+
+	// start out from the planned disks so that their sizes are read back relative to the plan
+	planDisks := plan.virtioDisks()
+	for i, d := range state.virtioDisks() {
+		*d = *planDisks[i]
+	}
+	// .. and the ssh keys, which are read back relative to the plan as well
+	state.SSHKeys = plan.SSHKeys
+	// .. and whether disks are given as a list or not
+	state.Disks = plan.Disks
+
+	err = UpdateVMResourceModelFromAPI(ctx, id, r.client, &state, VMStateEverything)
+	if err != nil {
+		resp.Diagnostics.AddError(
 			"Error Updating VM",
 			"Could not read back updated VM status, unexpected error: "+err.Error(),
 		)
 		return
 	}
 
-	if plan.Status.ValueString() != state.Status.ValueString() {
+	// if the status couldn't be read there's no telling what to do, leave it for the next run
+	if plan.Status.ValueString() != state.Status.ValueString() && !state.Status.IsNull() {
 		switch plan.Status.ValueString() {
 		case stateRunning:
 			tflog.Trace(ctx, "Starting VM since status in plan set to "+plan.Status.ValueString())
@@ -735,8 +1904,8 @@ func (r *vmResource) Update(ctx context.Context, req resource.UpdateRequest, res
 				return
 			}
 		case stateStopped:
-			tflog.Trace(ctx, "Starting VM since status in plan set to "+plan.Status.ValueString())
-			_, err := r.client.StopVm(vmr)
+			tflog.Trace(ctx, "Stopping VM since status in plan set to "+plan.Status.ValueString())
+			err := stopVM(ctx, r.client, vmr, plan.StopMode.ValueString())
 			if err != nil {
 				resp.Diagnostics.AddError(
 					"Error Updating VM",
@@ -747,6 +1916,8 @@ func (r *vmResource) Update(ctx context.Context, req resource.UpdateRequest, res
 		}
 	}
 
+	// the status is what it was just changed to, unless PVE tells otherwise
+	state.Status = plan.Status
 	err = UpdateVMResourceModelFromAPI(ctx, id, r.client, &state, VMStateStatus)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -756,6 +1927,11 @@ func (r *vmResource) Update(ctx context.Context, req resource.UpdateRequest, res
 		return
 	}
 
+	resp.Diagnostics.Append(state.collapseDisks(ctx)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	tflog.Trace(ctx, fmt.Sprintf("Setting state after updating VM to: %+v", state))
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
@@ -771,6 +1947,9 @@ func (r *vmResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 	if diags.HasError() {
 		return
 	}
+	defer func() {
+		resp.Diagnostics = withGuestContext(resp.Diagnostics, "VM", state.Node.ValueString(), state.VMID.ValueInt64())
+	}()
 
 	const deleteErrorSummary string = "Error Deleting VM"
 	tflog.Trace(ctx, fmt.Sprintf("Deleting VM %d", state.VMID.ValueInt64()))
@@ -786,7 +1965,7 @@ func (r *vmResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 
 	vmExists := false
 	for _, vm := range vms {
-		if int64(vm.Id) == state.VMID.ValueInt64() {
+		if int64(vm.Id) == state.VMID.ValueInt64() && vm.Type == pveapi.GuestQemu {
 			vmExists = true
 			break
 		}
@@ -800,8 +1979,13 @@ func (r *vmResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 	vmr := pveapi.NewVmRef(int(state.VMID.ValueInt64()))
 	vmr.SetNode(state.Node.ValueString())
 
+	resp.Diagnostics.Append(checkDeletable(ctx, r.client, vmr, deleteErrorSummary)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Does this fail if VM is stopped?
-	_, err = r.client.StopVm(vmr)
+	err = stopVM(ctx, r.client, vmr, state.StopMode.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			deleteErrorSummary,
@@ -821,11 +2005,16 @@ func (r *vmResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 	tflog.Trace(ctx, fmt.Sprintf("VM %d deleted", vmr.VmId()))
 }
 
-func (*vmResource) ImportState(_ context.Context, _ resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resp.Diagnostics.AddError(
-		"ImportState Not Yet Supported",
-		"Importing existing VM state is not currently supported, PRs welcome. :-)",
-	)
+func (r *vmResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importGuest(ctx, r.client, vmTypeQemu, req, resp)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("wait_for_ip"), true)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("stop_mode"), stopModeStop)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("agent_interface"), int64(0))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("agent_exclude_interfaces"), defaultAgentExcludeInterfaces)...)
 }
 
 func UpdateVMResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi.Client, model *vmResourceModel, sm VMStateMask) error {
@@ -834,6 +2023,7 @@ func UpdateVMResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi.
 	tflog.Trace(ctx, "Updating vmResourceModel from PVE API.", map[string]any{"vmid": vmid, "statemask": sm})
 
 	var config *pveapi.ConfigQemu
+	var rawConfig map[string]interface{}
 	var err error
 	if sm&VMStateConfig != 0 {
 		config, err = pveapi.NewConfigQemuFromApi(vmr, client)
@@ -841,84 +2031,85 @@ func UpdateVMResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi.
 			return err
 		}
 		tflog.Trace(ctx, fmt.Sprintf(".. updated config: %+v", config))
+
+		// the API client fills in its own defaults for some options that aren't set (e.g. onboot and
+		// cpu), those are read from the raw config so that unset matches the PVE defaults
+		rawConfig, err = client.GetVmConfig(vmr)
+		if err != nil {
+			return err
+		}
 	}
 
 	var status string
 	if sm&VMStateStatus != 0 {
-		state, err := client.GetVmState(vmr)
+		status, err = guestStatus(ctx, client, vmr)
 		if err != nil {
 			return err
 		}
-		var ok bool
-		status, ok = state["status"].(string)
-		if !ok {
-			return fmt.Errorf("status field in VM state was not a string but %T", state["status"])
-		}
 		tflog.Trace(ctx, ".. updated status: "+status)
 	}
 
-	var ipv4 string
-	if sm&VMStateNet != 0 && len(config.QemuNetworks) > 0 {
-		net0 := config.QemuNetworks[0]
+	// wait_for_ip is not backed by anything in PVE, treat it as enabled unless explicitly turned off
+	waitForIP := model.WaitForIP.IsNull() || model.WaitForIP.IsUnknown() || model.WaitForIP.ValueBool()
+	// .. and neither are agent_interface and agent_exclude_interfaces, use the defaults unless known
+	agentInterface := 0
+	if !model.AgentInterface.IsNull() && !model.AgentInterface.IsUnknown() {
+		agentInterface = int(model.AgentInterface.ValueInt64())
+	}
+	excludeInterfaces := defaultAgentExcludeInterfaces
+	if !model.AgentExcludeInterfaces.IsNull() && !model.AgentExcludeInterfaces.IsUnknown() {
+		excludeInterfaces = model.AgentExcludeInterfaces
+	}
+	var exclude []string
+	if diags := excludeInterfaces.ElementsAs(ctx, &exclude, false); diags.HasError() {
+		return errors.New("Unexpected error when reading agent_exclude_interfaces")
+	}
+
+	var ipv4, ipv6 string
+	var ips []string
+	var interfaces []pveapi.AgentNetworkInterface
+	if sm&VMStateNet != 0 && len(config.QemuNetworks[agentInterface]) > 0 {
+		nic := config.QemuNetworks[agentInterface]
 		macRe := regexp.MustCompile(`([a-fA-F0-9]{2}:){5}[a-fA-F0-9]{2}`)
 		mac := ""
-		if val, ok := net0["macaddr"]; ok {
+		if val, ok := nic["macaddr"]; ok {
 			mac = strings.ToLower(macRe.FindString(val.(string)))
 		}
-		if mac != "" && config.Agent == 1 {
-			dl := time.After(time.Minute * 5)
-			ipv4chan := make(chan string)
-			errchan := make(chan error)
-			stopchan := make(chan bool)
-			defer func() {
-				select {
-				case stopchan <- true:
-				default:
-				}
-			}()
-			go func() {
-				for {
-					select {
-					case <-stopchan:
-						return
-					default:
-					}
-
-					interfaces, err := client.GetVmAgentNetworkInterfaces(vmr)
-					if err != nil {
-						if strings.Contains(err.Error(), "500 QEMU guest agent is not running") {
-							time.Sleep(2 * time.Second)
-							continue
-						}
-						errchan <- err
-						return
-					}
-					if len(interfaces) > 0 {
-						for _, iface := range interfaces {
-							if strings.ToLower(iface.MACAddress) == mac {
-								for _, addr := range iface.IPAddresses {
-									if addr.IsGlobalUnicast() && addr.To4() != nil {
-										ipv4chan <- addr.String()
-										return
-									}
-								}
-							}
-						}
-					}
-
-					// if no valid IP read yet keep checking until deadline
-					time.Sleep(2 * time.Second)
-					continue
-				}
-			}()
-
-			select {
-			case <-dl:
-				return errors.New("timeout waiting for agent to start")
-			case err = <-errchan:
+		if mac != "" && config.Agent == 1 && !waitForIP {
+			// single attempt, if the agent isn't up (yet) we simply don't know any addresses
+			interfaces, err = agentNetworkInterfaces(client, vmr, exclude)
+			if err != nil {
 				return err
-			case ipv4 = <-ipv4chan:
 			}
+		} else if mac != "" && config.Agent == 1 {
+			// stops at the deadline, or right away when Terraform is interrupted and ctx is cancelled
+			waitCtx, cancel := context.WithTimeout(ctx, agentIPTimeout)
+			defer cancel()
+			interfaces, err = waitForAgentAddresses(waitCtx, client, vmr, mac, exclude)
+			if err != nil {
+				return err
+			}
+		}
+
+		ips = globalAddresses(interfaces, mac)
+		for _, ip := range ips {
+			addr := net.ParseIP(ip)
+			if addr == nil {
+				continue
+			}
+			if addr.To4() != nil && ipv4 == "" {
+				ipv4 = ip
+			} else if addr.To4() == nil && ipv6 == "" {
+				ipv6 = ip
+			}
+		}
+	}
+
+	var guestHostname, guestOS string
+	if sm&VMStateNet != 0 && config.Agent == 1 {
+		guestHostname, guestOS, err = agentGuestInfo(client, vmr)
+		if err != nil {
+			return err
 		}
 	}
 
@@ -932,30 +2123,104 @@ func UpdateVMResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi.
 			model.Name = types.StringValue(config.Name)
 		}
 
-		if config.Description == "" {
-			model.Description = types.StringNull()
+		model.Description = descriptionValue(model.Description, config.Description)
+		// looking up the config made the API client look up the VM, including its pool
+		model.Pool = poolValue(vmr)
+		model.Tags, err = tagsValue(ctx, config.Tags)
+		if err != nil {
+			return err
+		}
+
+		onboot, _ := rawConfig["onboot"].(float64)
+		model.Onboot = types.BoolValue(onboot == 1)
+		if config.Startup == "" {
+			model.Startup = types.StringNull()
 		} else {
-			model.Description = types.StringValue(config.Description)
+			model.Startup = types.StringValue(config.Startup)
 		}
+		model.Protection = types.BoolValue(config.Protection != nil && *config.Protection)
 
 		model.Agent = types.BoolValue(config.Agent > 0)
-		model.Sockets = types.Int64Value(int64(config.QemuSockets))
-		model.Cores = types.Int64Value(int64(config.QemuCores))
-		model.Memory = types.Int64Value(int64(config.Memory))
+		if cpu, ok := rawConfig["cpu"].(string); ok && cpu != "" {
+			model.CPU = types.StringValue(cpu)
+		} else {
+			model.CPU = types.StringNull()
+		}
+		// a template or VM created outside of terraform may not have these set at all
+		model.Sockets = types.Int64Value(rawConfigInt(rawConfig, "sockets", 1))
+		model.Cores = types.Int64Value(rawConfigInt(rawConfig, "cores", 1))
+		if config.QemuVcpus == 0 {
+			model.Vcpus = types.Int64Null()
+		} else {
+			model.Vcpus = types.Int64Value(int64(config.QemuVcpus))
+		}
+		model.Numa = types.BoolValue(config.QemuNuma != nil && *config.QemuNuma)
+		model.Memory = types.Int64Value(rawConfigInt(rawConfig, "memory", pveDefaultVMMemory))
+		// read from the raw config since the API client reads a balloon of 0 as not set
+		if balloon, ok := rawConfig["balloon"].(float64); ok {
+			model.Balloon = types.Int64Value(int64(balloon))
+		} else {
+			model.Balloon = types.Int64Null()
+		}
+		if shares, ok := rawConfig["shares"].(float64); ok {
+			model.Shares = types.Int64Value(int64(shares))
+		} else {
+			model.Shares = types.Int64Null()
+		}
 
-		if len(config.QemuNetworks) == 0 {
-			dm := vmNetModel{}
-			dmAttrs := dm.AttributeTypes()
-			model.Net = types.ObjectNull(dmAttrs)
+		if config.CIuser == "" {
+			model.CIUser = types.StringNull()
+		} else {
+			model.CIUser = types.StringValue(config.CIuser)
+		}
+		// cipassword is returned masked, keep whatever we have
+		model.SSHKeys = sshKeysValue(config.Sshkeys, model.SSHKeys)
+
+		for i, ipconfig := range model.ipconfigs() {
+			if c, ok := config.Ipconfig[i].(string); ok && c != "" {
+				*ipconfig = types.StringValue(c)
+			} else {
+				*ipconfig = types.StringNull()
+			}
+		}
+
+		if config.Nameserver == "" {
+			model.Nameserver = types.StringNull()
+		} else {
+			model.Nameserver = types.StringValue(config.Nameserver)
+		}
+		if config.Searchdomain == "" {
+			model.Searchdomain = types.StringNull()
 		} else {
+			model.Searchdomain = types.StringValue(config.Searchdomain)
+		}
+
+		macs := map[string]string{}
+		for i, net := range model.nets() {
 			dm := vmNetModel{}
-			net0 := config.QemuNetworks[0]
-			dm.readFromAPIConfig(&net0)
+			nic, ok := config.QemuNetworks[i]
+			if !ok {
+				*net = types.ObjectNull(dm.AttributeTypes())
+				continue
+			}
+			dm.readFromAPIConfig(&nic)
 			m, diags := types.ObjectValueFrom(ctx, dm.AttributeTypes(), dm)
 			if diags.HasError() {
-				return errors.New("Unexpected error when reading net from config")
+				return fmt.Errorf("Unexpected error when reading net%d from config", i)
+			}
+			*net = m
+			if !dm.MACAddress.IsNull() {
+				macs[fmt.Sprintf("net%d", i)] = dm.MACAddress.ValueString()
 			}
-			model.Net = m
+		}
+		if len(macs) > 0 {
+			l, diags := types.MapValueFrom(ctx, types.StringType, macs)
+			if diags.HasError() {
+				return errors.New("Unexpected error when reading MAC addresses from config")
+			}
+			model.MACAddresses = l
+		} else {
+			model.MACAddresses = types.MapNull(types.StringType)
 		}
 
 		if config.Disks == nil || config.Disks.VirtIO == nil {
@@ -978,82 +2243,82 @@ func UpdateVMResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi.
 			model.Virtio14 = types.ObjectNull(dmAttrs)
 			model.Virtio15 = types.ObjectNull(dmAttrs)
 		} else {
-			model.Virtio0, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_0)
+			model.Virtio0, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_0, model.Virtio0)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio1, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_1)
+			model.Virtio1, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_1, model.Virtio1)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio2, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_2)
+			model.Virtio2, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_2, model.Virtio2)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio3, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_3)
+			model.Virtio3, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_3, model.Virtio3)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio4, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_4)
+			model.Virtio4, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_4, model.Virtio4)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio5, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_5)
+			model.Virtio5, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_5, model.Virtio5)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio6, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_6)
+			model.Virtio6, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_6, model.Virtio6)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio7, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_7)
+			model.Virtio7, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_7, model.Virtio7)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio8, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_8)
+			model.Virtio8, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_8, model.Virtio8)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio9, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_9)
+			model.Virtio9, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_9, model.Virtio9)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio10, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_10)
+			model.Virtio10, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_10, model.Virtio10)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio11, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_11)
+			model.Virtio11, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_11, model.Virtio11)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio12, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_12)
+			model.Virtio12, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_12, model.Virtio12)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio13, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_13)
+			model.Virtio13, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_13, model.Virtio13)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio14, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_14)
+			model.Virtio14, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_14, model.Virtio14)
 			if err != nil {
 				return err
 			}
 
-			model.Virtio15, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_15)
+			model.Virtio15, err = virtioStateValueFromAPIConfig(ctx, config.Disks.VirtIO.Disk_15, model.Virtio15)
 			if err != nil {
 				return err
 			}
@@ -1087,9 +2352,25 @@ func UpdateVMResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi.
 				return err
 			}
 		}
+
+		model.Bios = types.StringValue(config.Bios)
+		// the API client defaults boot to the legacy "cdn" when it isn't set, only an explicit order is read back
+		if boot, ok := rawConfig["boot"].(string); ok && strings.HasPrefix(boot, "order=") {
+			var diags diag.Diagnostics
+			model.BootOrder, diags = types.ListValueFrom(ctx, types.StringType, strings.Split(strings.TrimPrefix(boot, "order="), ";"))
+			if diags.HasError() {
+				return errors.New("Unexpected error when reading boot order from config")
+			}
+		} else {
+			model.BootOrder = types.ListNull(types.StringType)
+		}
+		model.EFIDisk, err = efiDiskStateValueFromAPIConfig(ctx, config.EFIDisk, model.EFIDisk)
+		if err != nil {
+			return err
+		}
 	}
 	if sm&VMStateStatus != 0 {
-		model.Status = types.StringValue(status)
+		model.Status = statusValue(status, model.Status)
 	}
 	if sm&VMStateNet != 0 {
 		if ipv4 != "" {
@@ -1097,6 +2378,37 @@ func UpdateVMResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi.
 		} else {
 			model.IPV4Address = types.StringNull()
 		}
+		if ipv6 != "" {
+			model.IPV6Address = types.StringValue(ipv6)
+		} else {
+			model.IPV6Address = types.StringNull()
+		}
+
+		if len(ips) > 0 {
+			l, diags := types.ListValueFrom(ctx, types.StringType, ips)
+			if diags.HasError() {
+				return errors.New("Unexpected error when reading IP addresses from agent")
+			}
+			model.IPAddresses = l
+		} else {
+			model.IPAddresses = types.ListNull(types.StringType)
+		}
+
+		model.NetworkInterfaces, err = agentInterfacesValue(ctx, interfaces)
+		if err != nil {
+			return err
+		}
+
+		if guestHostname != "" {
+			model.GuestHostname = types.StringValue(guestHostname)
+		} else {
+			model.GuestHostname = types.StringNull()
+		}
+		if guestOS != "" {
+			model.GuestOS = types.StringValue(guestOS)
+		} else {
+			model.GuestOS = types.StringNull()
+		}
 	}
 
 	tflog.Trace(ctx, fmt.Sprintf("Updated vmResourceModel from PVE API, model is now %+v", model), map[string]any{"vmid": vmid, "statemask": sm})
@@ -1104,12 +2416,294 @@ func UpdateVMResourceModelFromAPI(ctx context.Context, vmid int, client *pveapi.
 	return nil
 }
 
-func virtioStateValueFromAPIConfig(ctx context.Context, c *pveapi.QemuVirtIOStorage) (types.Object, error) {
+// rawConfigInt reads an integer option from the raw VM config, where PVE returns some options as
+// numbers and others (like memory) as strings. Returns def if the option isn't set or can't be read.
+func rawConfigInt(raw map[string]any, key string, def int64) int64 {
+	switch v := raw[key].(type) {
+	case float64:
+		return int64(v)
+	case string:
+		// memory can be given as "current=<mb>[,...]"
+		v, _, _ = strings.Cut(strings.TrimPrefix(v, "current="), ",")
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+// waitForAgentAddresses polls the guest agent until it reports a global address for the NIC with mac,
+// returning all the interfaces reported but the excluded ones. Gives up when ctx is done.
+func waitForAgentAddresses(ctx context.Context, client *pveapi.Client, vmr *pveapi.VmRef, mac string, exclude []string) ([]pveapi.AgentNetworkInterface, error) {
+	for {
+		interfaces, err := agentNetworkInterfaces(client, vmr, exclude)
+		if err != nil {
+			return nil, err
+		}
+		// any global address will do, the guest might only have IPv6
+		if len(globalAddresses(interfaces, mac)) > 0 {
+			return interfaces, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, errors.New("timeout waiting for the guest agent to report an IP address")
+			}
+			return nil, ctx.Err()
+		case <-time.After(agentPollInterval):
+		}
+	}
+}
+
+// defaultAgentExcludeInterfaces leaves out loopback and the interfaces container runtimes and
+// hypervisors create in a guest, their addresses are internal to the guest.
+var defaultAgentExcludeInterfaces = types.SetValueMust(types.StringType, []attr.Value{
+	types.StringValue("lo"),
+	types.StringValue("docker*"),
+	types.StringValue("br-*"),
+	types.StringValue("veth*"),
+	types.StringValue("virbr*"),
+	types.StringValue("cni*"),
+	types.StringValue("flannel*"),
+	types.StringValue("cali*"),
+})
+
+// agentNetworkInterfaces asks the guest agent for the network interfaces of the guest, none if the
+// agent isn't running. Interfaces with a name matching any of exclude are left out.
+func agentNetworkInterfaces(client *pveapi.Client, vmr *pveapi.VmRef, exclude []string) ([]pveapi.AgentNetworkInterface, error) {
+	interfaces, err := client.GetVmAgentNetworkInterfaces(vmr)
+	if err != nil {
+		if strings.Contains(err.Error(), "500 QEMU guest agent is not running") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	kept := []pveapi.AgentNetworkInterface{}
+	for _, iface := range interfaces {
+		if !interfaceExcluded(iface.Name, exclude) {
+			kept = append(kept, iface)
+		}
+	}
+	return kept, nil
+}
+
+// interfaceExcluded tells if name matches any of exclude, a trailing * matching any suffix.
+func interfaceExcluded(name string, exclude []string) bool {
+	for _, e := range exclude {
+		if prefix, ok := strings.CutSuffix(e, "*"); ok && strings.HasPrefix(name, prefix) {
+			return true
+		} else if !ok && name == e {
+			return true
+		}
+	}
+	return false
+}
+
+// globalAddresses returns the global unicast addresses of the interface with mac.
+func globalAddresses(interfaces []pveapi.AgentNetworkInterface, mac string) []string {
+	found := []string{}
+	for _, iface := range interfaces {
+		if strings.ToLower(iface.MACAddress) == mac {
+			for _, addr := range iface.IPAddresses {
+				if addr.IsGlobalUnicast() {
+					found = append(found, addr.String())
+				}
+			}
+		}
+	}
+
+	return found
+}
+
+type agentInterfaceModel struct {
+	Name        types.String `tfsdk:"name"`
+	MACAddress  types.String `tfsdk:"mac_address"`
+	IPAddresses types.List   `tfsdk:"ip_addresses"`
+}
+
+func (agentInterfaceModel) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"name":         types.StringType,
+		"mac_address":  types.StringType,
+		"ip_addresses": types.ListType{ElemType: types.StringType},
+	}
+}
+
+// agentInterfacesValue returns the interfaces reported by the guest agent as network_interfaces, null
+// if there are none.
+func agentInterfacesValue(ctx context.Context, interfaces []pveapi.AgentNetworkInterface) (types.List, error) {
+	elemType := types.ObjectType{AttrTypes: agentInterfaceModel{}.AttributeTypes()}
+	if len(interfaces) == 0 {
+		return types.ListNull(elemType), nil
+	}
+
+	models := make([]agentInterfaceModel, 0, len(interfaces))
+	for _, iface := range interfaces {
+		addrs := make([]string, 0, len(iface.IPAddresses))
+		for _, addr := range iface.IPAddresses {
+			addrs = append(addrs, addr.String())
+		}
+		l, diags := types.ListValueFrom(ctx, types.StringType, addrs)
+		if diags.HasError() {
+			return types.ListNull(elemType), errors.New("Unexpected error when reading interface addresses from agent")
+		}
+		models = append(models, agentInterfaceModel{
+			Name:        types.StringValue(iface.Name),
+			MACAddress:  types.StringValue(strings.ToLower(iface.MACAddress)),
+			IPAddresses: l,
+		})
+	}
+
+	l, diags := types.ListValueFrom(ctx, elemType, models)
+	if diags.HasError() {
+		return types.ListNull(elemType), errors.New("Unexpected error when reading network interfaces from agent")
+	}
+	return l, nil
+}
+
+// cloneStorageErrorRe matches the errors PVE gives when a linked clone can't use the storage of the
+// template, e.g. because it's local to another node or doesn't support linked clones.
+var cloneStorageErrorRe = regexp.MustCompile(`(?i)(linked clone feature is not supported|can't clone (vm )?to non-shared storage|storage '[^']+' is not available on node|can't clone vm to node)`)
+
+// cloneSourceIsTemplate tells if src is a template, PVE can only make linked clones of templates.
+func cloneSourceIsTemplate(client *pveapi.Client, src *pveapi.VmRef, clone string) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	// a ref of its own since looking it up would otherwise fill in src
+	config, err := client.GetVmConfig(pveapi.NewVmRef(src.VmId()))
+	if err != nil {
+		diags.AddError(
+			"Error Creating VM",
+			fmt.Sprintf("Could not read config of '%s' to clone, unexpected error: %s", clone, err.Error()),
+		)
+		return false, diags
+	}
+
+	template, _ := config["template"].(float64)
+	return template == 1, diags
+}
+
+// checkCloneSnapshot verifies that the source VM has the snapshot to clone.
+func checkCloneSnapshot(client *pveapi.Client, src *pveapi.VmRef, clone string, snapshot string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// a ref of its own since looking it up would otherwise fill in src
+	snapshots, _, err := client.ListQemuSnapshot(pveapi.NewVmRef(src.VmId()))
+	if err != nil {
+		diags.AddError(
+			"Error Creating VM",
+			fmt.Sprintf("Could not list snapshots of '%s' to clone, unexpected error: %s", clone, err.Error()),
+		)
+		return diags
+	}
+
+	names := []string{}
+	data, _ := snapshots["data"].([]any)
+	for _, d := range data {
+		s, ok := d.(map[string]any)
+		if !ok {
+			continue
+		}
+		// "current" is listed as well but isn't a snapshot
+		if name, ok := s["name"].(string); ok && name != "current" {
+			if name == snapshot {
+				return diags
+			}
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	diags.AddAttributeError(
+		path.Root("clone_snapshot"),
+		"Snapshot Not Found",
+		fmt.Sprintf("'%s' has no snapshot named '%s', its snapshots are: %s", clone, snapshot, strings.Join(names, ", ")),
+	)
+
+	return diags
+}
+
+// cloneVM does what ConfigQemu.CloneVm does but waits for the clone task itself, the API client only
+// waits as long as the provider timeout and large templates can take a lot longer than that to clone.
+// A full clone is made onto storage if it isn't "", from snapshot if it isn't "", and put in pool if it
+// isn't "".
+func cloneVM(ctx context.Context, client *pveapi.Client, config *pveapi.ConfigQemu, src *pveapi.VmRef, vmr *pveapi.VmRef, storage string, snapshot string, pool string, timeout time.Duration) error {
+	vmr.SetVmType(vmTypeQemu)
+
+	fullClone := 1
+	if config.FullClone != nil {
+		fullClone = *config.FullClone
+	}
+	params := map[string]any{
+		"newid":  vmr.VmId(),
+		"target": vmr.Node(),
+		"name":   config.Name,
+		"full":   strconv.Itoa(fullClone),
+	}
+	if storage != "" {
+		params["storage"] = storage
+	}
+	if snapshot != "" {
+		params["snapname"] = snapshot
+	}
+	// tags can't be given when cloning, they're set along with the rest of the config after
+	if config.Description != "" {
+		params["description"] = config.Description
+	}
+	if pool != "" {
+		params["pool"] = pool
+	}
+
+	body, err := client.CreateItemReturnStatus(params, fmt.Sprintf("/nodes/%s/qemu/%d/clone", src.Node(), src.VmId()))
+	if err != nil {
+		return err
+	}
+
+	upid, err := upidFromResponse(body)
+	if err != nil {
+		return err
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("Cloning VM %d into %d, waiting up to %s", src.VmId(), vmr.VmId(), timeout))
+	return waitForTask(ctx, client, upid, timeout)
+}
+
+// createTimeout returns timeouts.create if set, otherwise the provider-wide task timeout.
+func createTimeout(ctx context.Context, o types.Object, client *pveapi.Client) (time.Duration, error) {
+	fallback := time.Duration(client.TaskTimeout) * time.Second
+	if o.IsNull() || o.IsUnknown() {
+		return fallback, nil
+	}
+
+	var t vmTimeoutsModel
+	diags := o.As(ctx, &t, basetypes.ObjectAsOptions{})
+	if diags.HasError() {
+		return 0, fmt.Errorf("failed to read timeouts")
+	}
+	if t.Create.IsNull() || t.Create.IsUnknown() {
+		return fallback, nil
+	}
+
+	return time.ParseDuration(t.Create.ValueString())
+}
+
+// virtioStateValueFromAPIConfig reads the disk from c, prior is the disk as currently known (if any)
+// and is used to keep the unit its size is given in.
+func virtioStateValueFromAPIConfig(ctx context.Context, c *pveapi.QemuVirtIOStorage, prior types.Object) (types.Object, error) {
 	dm := virtioModel{} // create instance to gain access to AttributeTypes() below for nil branch...
 	if c == nil {
 		return types.ObjectNull(dm.AttributeTypes()), nil
 	}
 
+	if !prior.IsNull() && !prior.IsUnknown() {
+		diags := prior.As(ctx, &dm, basetypes.ObjectAsOptions{})
+		if diags.HasError() {
+			return types.Object{}, errors.New("Unexpected error when reading prior virtio state")
+		}
+	}
 	dm.readFromAPIConfig(c)
 	m, diags := types.ObjectValueFrom(ctx, dm.AttributeTypes(), dm)
 	if diags.HasError() {
@@ -1119,6 +2713,37 @@ func virtioStateValueFromAPIConfig(ctx context.Context, c *pveapi.QemuVirtIOStor
 	return m, nil
 }
 
+// agentGuestInfo asks the guest agent for the guest's host name and OS (pretty) name. Both are empty
+// if the agent isn't running.
+func agentGuestInfo(client *pveapi.Client, vmr *pveapi.VmRef) (hostname string, os string, err error) {
+	agentResult := func(command string) (map[string]any, error) {
+		url := fmt.Sprintf("/nodes/%s/qemu/%d/agent/%s", vmr.Node(), vmr.VmId(), command)
+		data, err := client.GetItemConfigMapStringInterface(url, "agent", command)
+		if err != nil {
+			if strings.Contains(err.Error(), "not running") {
+				return nil, nil
+			}
+			return nil, err
+		}
+		result, _ := data["result"].(map[string]any)
+		return result, nil
+	}
+
+	result, err := agentResult("get-host-name")
+	if err != nil {
+		return "", "", err
+	}
+	hostname, _ = result["host-name"].(string)
+
+	result, err = agentResult("get-osinfo")
+	if err != nil {
+		return "", "", err
+	}
+	os, _ = result["pretty-name"].(string)
+
+	return hostname, os, nil
+}
+
 func ideStateValueFromAPIConfig(ctx context.Context, c *pveapi.QemuIdeStorage) (types.Object, error) {
 	dm := ideModel{} // create instance to gain access to AttributeTypes() below for nil branch...
 	if c == nil {
@@ -1134,27 +2759,107 @@ func ideStateValueFromAPIConfig(ctx context.Context, c *pveapi.QemuIdeStorage) (
 	return m, nil
 }
 
+func efiDiskStateValueFromAPIConfig(ctx context.Context, c pveapi.QemuDevice, prior types.Object) (types.Object, error) {
+	dm := efiDiskModel{}
+	if len(c) == 0 {
+		return types.ObjectNull(dm.AttributeTypes()), nil
+	}
+
+	if !prior.IsNull() && !prior.IsUnknown() {
+		diags := prior.As(ctx, &dm, basetypes.ObjectAsOptions{})
+		if diags.HasError() {
+			return types.Object{}, errors.New("Unexpected error when reading prior efidisk state")
+		}
+	} else {
+		dm.Format = types.StringNull()
+	}
+	dm.readFromAPIConfig(&c)
+	m, diags := types.ObjectValueFrom(ctx, dm.AttributeTypes(), dm)
+	if diags.HasError() {
+		return types.Object{}, errors.New("Unexpected error when reading efidisk from config")
+	}
+
+	return m, nil
+}
+
 func apiConfigFromVMResourceModel(ctx context.Context, model *vmResourceModel, config *pveapi.ConfigQemu) error {
 	// Node set via VmRef
 	// VMID set via VmRef
 	config.Name = model.Name.ValueString()
 	config.Description = model.Description.ValueString()
+	if !model.Tags.IsUnknown() {
+		tags, err := joinTags(ctx, model.Tags)
+		if err != nil {
+			return err
+		}
+		config.Tags = tags
+	}
+
+	onboot := model.Onboot.ValueBool()
+	config.Onboot = &onboot
+	if !model.Startup.IsUnknown() {
+		config.Startup = model.Startup.ValueString()
+	}
+	protection := model.Protection.ValueBool()
+	config.Protection = &protection
 
 	config.Agent = 0
 	if model.Agent.ValueBool() {
 		config.Agent = 1
 	}
 
+	if !model.CPU.IsUnknown() {
+		config.QemuCpu = model.CPU.ValueString()
+	}
+	// unknown values are inherited from the template when cloning, see mergeUnmanagedVMConfig
 	config.QemuSockets = int(model.Sockets.ValueInt64())
 	config.QemuCores = int(model.Cores.ValueInt64())
+	if !model.Vcpus.IsNull() && !model.Vcpus.IsUnknown() {
+		config.QemuVcpus = int(model.Vcpus.ValueInt64())
+	}
+	if !model.Numa.IsUnknown() {
+		numa := model.Numa.ValueBool()
+		config.QemuNuma = &numa
+	}
 	config.Memory = int(model.Memory.ValueInt64())
+	if !model.Balloon.IsNull() && !model.Balloon.IsUnknown() {
+		// 0 isn't sent by the API client, see vmExtraParams
+		config.Balloon = int(model.Balloon.ValueInt64())
+	}
+
+	if !model.CIUser.IsUnknown() {
+		config.CIuser = model.CIUser.ValueString()
+	}
+	config.CIpassword = model.CIPassword.ValueString()
+	if !model.SSHKeys.IsUnknown() {
+		// the API client url-encodes the keys and adds a trailing newline itself
+		config.Sshkeys = strings.TrimRight(model.SSHKeys.ValueString(), "\n")
+	}
+	if !model.Nameserver.IsUnknown() {
+		config.Nameserver = model.Nameserver.ValueString()
+	}
+	if !model.Searchdomain.IsUnknown() {
+		config.Searchdomain = model.Searchdomain.ValueString()
+	}
+	config.Ipconfig = pveapi.IpconfigMap{}
+	for i, ipconfig := range model.ipconfigs() {
+		if !ipconfig.IsNull() && !ipconfig.IsUnknown() {
+			config.Ipconfig[i] = ipconfig.ValueString()
+		}
+	}
 
-	if !model.Net.IsNull() && !model.Net.IsUnknown() {
-		net0, err := vmNetAPIConfigFromStateValue(ctx, model.Net)
+	for i, net := range model.nets() {
+		if net.IsNull() || net.IsUnknown() {
+			continue
+		}
+		nic, err := vmNetAPIConfigFromStateValue(ctx, *net)
 		if err != nil {
 			return err
 		}
-		config.QemuNetworks = pveapi.QemuDevices{0: net0}
+		if config.QemuNetworks == nil {
+			config.QemuNetworks = pveapi.QemuDevices{}
+		}
+		config.QemuNetworks[i] = nic
 	}
 
 	// even if we have no disks in state we need empty structs for API client to consider it and e.g. emit delete actions
@@ -1251,9 +2956,437 @@ func apiConfigFromVMResourceModel(ctx context.Context, model *vmResourceModel, c
 		}
 	}
 
+	if !model.Bios.IsUnknown() {
+		config.Bios = model.Bios.ValueString()
+	}
+	if !model.BootOrder.IsNull() && !model.BootOrder.IsUnknown() {
+		var order []string
+		diags := model.BootOrder.ElementsAs(ctx, &order, false)
+		if diags.HasError() {
+			return errors.New("unable to read boot_order from model")
+		}
+		config.Boot = "order=" + strings.Join(order, ";")
+	}
+	if !model.EFIDisk.IsNull() && !model.EFIDisk.IsUnknown() {
+		var dm efiDiskModel
+		diags := model.EFIDisk.As(ctx, &dm, basetypes.ObjectAsOptions{})
+		if diags.HasError() {
+			return errors.New("unable to create config object from efidisk state value")
+		}
+		config.EFIDisk = pveapi.QemuDevice{}
+		dm.writeToAPIConfig(&config.EFIDisk)
+	}
+
 	return nil
 }
 
+// vmExtraParams returns the options in the model the API client doesn't send itself, these need to be
+// set separately.
+func vmExtraParams(model *vmResourceModel) map[string]any {
+	params := map[string]any{}
+	if !model.Balloon.IsNull() && !model.Balloon.IsUnknown() && model.Balloon.ValueInt64() == 0 {
+		params["balloon"] = 0
+	}
+	if !model.Shares.IsNull() && !model.Shares.IsUnknown() {
+		params["shares"] = model.Shares.ValueInt64()
+	}
+	return params
+}
+
+// cosmeticVMAttributes are the attributes that only describe the VM and can be changed without touching
+// the guest itself. stop_mode, agent_interface and agent_exclude_interfaces only live in state and
+// are included for the same reason.
+var cosmeticVMAttributes = map[string]bool{
+	"name":                     true,
+	"description":              true,
+	"tags":                     true,
+	"pool":                     true,
+	"stop_mode":                true,
+	"agent_interface":          true,
+	"agent_exclude_interfaces": true,
+}
+
+// onlyCosmeticVMChanges tells if going from prior to plan only changes cosmetic attributes. Values left
+// unknown in the plan aren't changes asked for and are ignored.
+func onlyCosmeticVMChanges(prior *vmResourceModel, plan *vmResourceModel) bool {
+	prev := reflect.ValueOf(*prior)
+	next := reflect.ValueOf(*plan)
+	t := next.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if cosmeticVMAttributes[t.Field(i).Tag.Get("tfsdk")] {
+			continue
+		}
+		n, ok := next.Field(i).Interface().(attr.Value)
+		if !ok || n.IsUnknown() {
+			continue
+		}
+		if !n.Equal(prev.Field(i).Interface().(attr.Value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// cosmeticVMParams returns the API params setting the cosmetic attributes known in the plan, removing
+// tags that are no longer in it.
+func cosmeticVMParams(ctx context.Context, prior *vmResourceModel, plan *vmResourceModel) (map[string]any, error) {
+	params := map[string]any{}
+	if !plan.Name.IsUnknown() && !plan.Name.IsNull() {
+		params["name"] = plan.Name.ValueString()
+	}
+	if !plan.Description.IsUnknown() && !plan.Description.IsNull() {
+		params["description"] = plan.Description.ValueString()
+	}
+	if plan.Tags.IsNull() && !prior.Tags.IsNull() {
+		params["delete"] = "tags"
+	} else if !plan.Tags.IsUnknown() && !plan.Tags.IsNull() {
+		tags, err := joinTags(ctx, plan.Tags)
+		if err != nil {
+			return nil, err
+		}
+		params["tags"] = tags
+	}
+	return params, nil
+}
+
+// mergeUnmanagedVMConfig copies settings we don't model from the VM's current config into config,
+// so that updating e.g. a disk's size doesn't silently reset its unmodeled options. Top-level keys
+// are only sent by the API client when set so those are left alone already, but disks and network
+// devices are sent as a whole and need their unmodeled parts carried over.
+func mergeUnmanagedVMConfig(config *pveapi.ConfigQemu, current *pveapi.ConfigQemu) {
+	// values left unknown in the plan to be inherited, see keepClonedModifier
+	if config.QemuSockets == 0 {
+		config.QemuSockets = current.QemuSockets
+	}
+	if config.QemuCores == 0 {
+		config.QemuCores = current.QemuCores
+	}
+	if config.QemuNuma == nil {
+		config.QemuNuma = current.QemuNuma
+	}
+	if config.Memory == 0 {
+		config.Memory = current.Memory
+	}
+	if config.Bios == "" {
+		config.Bios = current.Bios
+	}
+
+	// the API client allocates a new efidisk whenever one is given, keep the one the VM already has
+	if len(current.EFIDisk) > 0 {
+		config.EFIDisk = nil
+	}
+
+	// a configured mac_address is kept over the one the NIC currently has (e.g. a fresh one given by cloning)
+	for id, nic := range config.QemuNetworks {
+		currentNic, ok := current.QemuNetworks[id]
+		if !ok {
+			continue
+		}
+		for k, v := range currentNic {
+			if _, ok := nic[k]; !ok && k != "id" {
+				nic[k] = v
+			}
+		}
+	}
+
+	if config.Disks == nil || config.Disks.VirtIO == nil || current.Disks == nil || current.Disks.VirtIO == nil {
+		return
+	}
+	currentDisks := virtioStorages(current.Disks.VirtIO)
+	for i, d := range virtioStorages(config.Disks.VirtIO) {
+		c := currentDisks[i]
+		if d == nil || d.Disk == nil || c == nil || c.Disk == nil {
+			continue
+		}
+		d.Disk.Bandwidth = c.Disk.Bandwidth
+		d.Disk.ReadOnly = c.Disk.ReadOnly
+		d.Disk.Replicate = c.Disk.Replicate
+		d.Disk.Serial = c.Disk.Serial
+		d.Disk.WorldWideName = c.Disk.WorldWideName
+	}
+}
+
+var diskInterfaceRe = regexp.MustCompile(`^virtio(\d|1[0-5])$`)
+
+var bootDeviceRe = regexp.MustCompile(`^(virtio(\d|1[0-5])|ide[0-3]|net[0-7])$`)
+
+// expandDisks puts the entries of the disks list into their virtio slots, so that the rest of the
+// resource only has to deal with the slots. The list itself is left as is.
+func (m *vmResourceModel) expandDisks(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if m.Disks.IsNull() || m.Disks.IsUnknown() {
+		return diags
+	}
+
+	slots := m.virtioDisks()
+	seen := map[string]bool{}
+	for _, e := range m.Disks.Elements() {
+		d, ok := e.(types.Object)
+		if !ok || d.IsNull() || d.IsUnknown() {
+			continue
+		}
+		iface, o, objDiags := virtioFromDisk(d)
+		diags.Append(objDiags...)
+		if diags.HasError() {
+			return diags
+		}
+
+		match := diskInterfaceRe.FindStringSubmatch(iface)
+		if match == nil {
+			continue
+		}
+		if seen[iface] {
+			diags.AddAttributeError(
+				path.Root("disks"),
+				"Duplicate Disk Interface",
+				fmt.Sprintf("The interface %s is used by more than one disk.", iface),
+			)
+			return diags
+		}
+		seen[iface] = true
+
+		n, _ := strconv.Atoi(match[1])
+		*slots[n] = o
+	}
+
+	return diags
+}
+
+// collapseDisks moves the virtio slots back into the disks list if the list is in use, keeping the
+// order of the disks already in it and appending any others.
+func (m *vmResourceModel) collapseDisks(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if m.Disks.IsNull() {
+		return diags
+	}
+
+	slots := m.virtioDisks()
+	ifaces := make([]string, 0, len(slots))
+	for _, e := range m.Disks.Elements() {
+		if d, ok := e.(types.Object); ok {
+			iface, _ := d.Attributes()["interface"].(types.String)
+			ifaces = append(ifaces, iface.ValueString())
+		}
+	}
+	for i := range slots {
+		iface := fmt.Sprintf("virtio%d", i)
+		found := false
+		for _, p := range ifaces {
+			found = found || p == iface
+		}
+		if !found {
+			ifaces = append(ifaces, iface)
+		}
+	}
+
+	disks := []attr.Value{}
+	for _, iface := range ifaces {
+		match := diskInterfaceRe.FindStringSubmatch(iface)
+		if match == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(match[1])
+		if slots[n].IsNull() || slots[n].IsUnknown() {
+			continue
+		}
+		d, objDiags := diskFromVirtio(iface, *slots[n])
+		diags.Append(objDiags...)
+		if diags.HasError() {
+			return diags
+		}
+		disks = append(disks, d)
+	}
+
+	l, listDiags := types.ListValue(types.ObjectType{AttrTypes: diskAttributeTypes()}, disks)
+	diags.Append(listDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	m.Disks = l
+	for _, slot := range slots {
+		*slot = types.ObjectNull(virtioModel{}.AttributeTypes())
+	}
+
+	return diags
+}
+
+func (m *vmResourceModel) nets() []*types.Object {
+	return []*types.Object{
+		&m.Net, &m.Net1, &m.Net2, &m.Net3, &m.Net4, &m.Net5, &m.Net6, &m.Net7,
+	}
+}
+
+func (m *vmResourceModel) ipconfigs() []*types.String {
+	return []*types.String{
+		&m.Ipconfig0, &m.Ipconfig1, &m.Ipconfig2, &m.Ipconfig3, &m.Ipconfig4, &m.Ipconfig5, &m.Ipconfig6, &m.Ipconfig7,
+	}
+}
+
+func (m *vmResourceModel) virtioDisks() []*types.Object {
+	return []*types.Object{
+		&m.Virtio0, &m.Virtio1, &m.Virtio2, &m.Virtio3, &m.Virtio4, &m.Virtio5, &m.Virtio6, &m.Virtio7,
+		&m.Virtio8, &m.Virtio9, &m.Virtio10, &m.Virtio11, &m.Virtio12, &m.Virtio13, &m.Virtio14, &m.Virtio15,
+	}
+}
+
+var diskSizeRe = regexp.MustCompile(`^(\d+)([KMG]?)$`)
+
+// diskSizeKiB parses a disk size like "512M" or "30G", a bare number is in GB. Returns 0 if the
+// size can't be parsed, the schema validator makes sure that doesn't happen for configured sizes.
+func diskSizeKiB(size string) int64 {
+	m := diskSizeRe.FindStringSubmatch(size)
+	if m == nil {
+		return 0
+	}
+	n, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	switch m[2] {
+	case "K":
+		return n
+	case "M":
+		return n * 1024
+	}
+	return n * 1024 * 1024
+}
+
+// formatDiskSize formats size, in KiB, exactly in the largest unit it's a whole number of.
+func formatDiskSize(size int64) string {
+	switch {
+	case size%(1024*1024) == 0:
+		return fmt.Sprintf("%dG", size/(1024*1024))
+	case size%1024 == 0:
+		return fmt.Sprintf("%dM", size/1024)
+	}
+	return fmt.Sprintf("%dK", size)
+}
+
+// diskSizeValue converts size to a state value. The value is the exact size of the disk, so that disks
+// that aren't a whole number of GB (e.g. a cloud image) can be configured to match, but a prior value
+// of the same size in another unit (e.g. "1024M" for a 1G disk) is kept as is.
+func diskSizeValue(size pveapi.QemuDiskSize, prior types.String) types.String {
+	if !prior.IsNull() && !prior.IsUnknown() && diskSizeKiB(prior.ValueString()) == int64(size) {
+		return prior
+	}
+	return types.StringValue(formatDiskSize(int64(size)))
+}
+
+// sshKeysValue converts keys to a state value. PVE stores the keys with a trailing newline which
+// configs written as heredocs may or may not have, so a prior value only differing in that is kept.
+func sshKeysValue(keys string, prior types.String) types.String {
+	keys = strings.TrimRight(keys, "\n")
+	if keys == "" {
+		return types.StringNull()
+	}
+	if !prior.IsNull() && !prior.IsUnknown() && strings.TrimRight(prior.ValueString(), "\n") == keys {
+		return prior
+	}
+	return types.StringValue(keys)
+}
+
+type virtioDiskChanges struct {
+	moves map[string]string // disk -> new storage
+}
+
+// diffVirtioDisks returns the virtio disks that need to be moved to go from state to plan. PVE can't
+// shrink disks, asking for it is reported as an error rather than silently ignored (as the API client
+// does when growing disks).
+func diffVirtioDisks(ctx context.Context, state *vmResourceModel, plan *vmResourceModel) (virtioDiskChanges, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	changes := virtioDiskChanges{
+		moves: map[string]string{},
+	}
+
+	planDisks := plan.virtioDisks()
+	for i, o := range state.virtioDisks() {
+		if o.IsNull() || o.IsUnknown() || planDisks[i].IsNull() || planDisks[i].IsUnknown() {
+			continue
+		}
+
+		var prev, next virtioModel
+		diags.Append(o.As(ctx, &prev, basetypes.ObjectAsOptions{})...)
+		diags.Append(planDisks[i].As(ctx, &next, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return changes, diags
+		}
+		if next.Media.ValueString() != mediaDisk || prev.Media.ValueString() != mediaDisk {
+			continue
+		}
+
+		name := fmt.Sprintf("virtio%d", i)
+		if next.Storage.ValueString() != "" && next.Storage.ValueString() != prev.Storage.ValueString() {
+			changes.moves[name] = next.Storage.ValueString()
+		}
+
+		if next.Size.IsNull() || next.Size.IsUnknown() {
+			continue
+		}
+		if diskSizeKiB(next.Size.ValueString()) < diskSizeKiB(prev.Size.ValueString()) {
+			diags.AddAttributeError(
+				path.Root(name).AtName("size"),
+				"Disk Shrink Not Supported",
+				fmt.Sprintf("Disk %s can't be shrunk from %s to %s, Proxmox only supports growing disks.", name, prev.Size.ValueString(), next.Size.ValueString()),
+			)
+		}
+	}
+
+	return changes, diags
+}
+
+// checkCloneDiskSizes compares the virtio disks in the plan with those of the template a clone is made
+// of. The clone gets the disks of the template, which are grown to the planned size but can't be shrunk.
+func checkCloneDiskSizes(ctx context.Context, plan *vmResourceModel, template *pveapi.ConfigQemu) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if template.Disks == nil || template.Disks.VirtIO == nil {
+		return diags
+	}
+
+	templateDisks := virtioStorages(template.Disks.VirtIO)
+	for i, o := range plan.virtioDisks() {
+		t := templateDisks[i]
+		if o.IsNull() || o.IsUnknown() || t == nil || t.Disk == nil {
+			continue
+		}
+
+		var next virtioModel
+		diags.Append(o.As(ctx, &next, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return diags
+		}
+		if next.Media.ValueString() != mediaDisk || next.Size.IsNull() || next.Size.IsUnknown() {
+			continue
+		}
+
+		name := fmt.Sprintf("virtio%d", i)
+		if cloned := int64(t.Disk.SizeInKibibytes); diskSizeKiB(next.Size.ValueString()) < cloned {
+			diags.AddAttributeError(
+				path.Root(name).AtName("size"),
+				"Disk Shrink Not Supported",
+				fmt.Sprintf("Disk %s of the template is %s, it can't be shrunk to %s for the clone since Proxmox only supports growing disks.", name, formatDiskSize(cloned), next.Size.ValueString()),
+			)
+		}
+	}
+
+	return diags
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func virtioStorages(d *pveapi.QemuVirtIODisks) []*pveapi.QemuVirtIOStorage {
+	return []*pveapi.QemuVirtIOStorage{
+		d.Disk_0, d.Disk_1, d.Disk_2, d.Disk_3, d.Disk_4, d.Disk_5, d.Disk_6, d.Disk_7,
+		d.Disk_8, d.Disk_9, d.Disk_10, d.Disk_11, d.Disk_12, d.Disk_13, d.Disk_14, d.Disk_15,
+	}
+}
+
 func virtioAPIConfigFromStateValue(ctx context.Context, o basetypes.ObjectValue) (*pveapi.QemuVirtIOStorage, error) {
 	if o.IsNull() {
 		return nil, nil
@@ -1299,6 +3432,53 @@ func vmNetAPIConfigFromStateValue(ctx context.Context, o basetypes.ObjectValue)
 	return c, nil
 }
 
+// maxIDAttempts bounds how many times creating a guest with an auto-assigned ID is tried. PVE creates
+// the guest config atomically, so concurrent runs handed the same ID by GetNextID can't both succeed;
+// the losing one asks for a new ID after a short random delay.
+const maxIDAttempts = 10
+
+// idCollisionBackoff returns a random delay before retrying with a new ID, growing with each collision
+// so that runs racing for IDs drift apart.
+func idCollisionBackoff(collisions int) time.Duration {
+	return time.Duration(rand.Int63n(int64(collisions) * int64(500*time.Millisecond)))
+}
+
+// waitIDCollisionBackoff waits idCollisionBackoff before retrying with a new ID, returning early
+// when ctx is done, e.g. when Terraform is interrupted.
+func waitIDCollisionBackoff(ctx context.Context, collisions int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(idCollisionBackoff(collisions)):
+		return nil
+	}
+}
+
+// vmIDTakenRe matches the errors PVE gives when creating or cloning to a VM ID that's already taken.
+var vmIDTakenRe = regexp.MustCompile(`unable to create VM \d+( \- VM \d+ already exists|: config file already exists)`)
+
+// vmIDTakenError is the error for creating a VM with an ID that's already taken by another guest. For a
+// vmid set in config that's the end of it, an auto-assigned one was only taken after maxIDAttempts tries
+// with a new ID, most likely by guests being created at the same time, and trying again may well work.
+func vmIDTakenError(id int, vmid basetypes.Int64Value, err error) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if vmid.IsUnknown() {
+		diags.AddAttributeError(
+			path.Root("vmid"),
+			"VM ID Already Exists",
+			fmt.Sprintf("The next free VM ID was taken by another guest before the VM could be created, %d times in a row, last with ID %d. Other guests are likely being created at the same time, try again, or set vmid to a free ID.\n\n%s", maxIDAttempts, id, err.Error()),
+		)
+		return diags
+	}
+
+	diags.AddAttributeError(
+		path.Root("vmid"),
+		"VM ID Already Exists",
+		fmt.Sprintf("A guest with ID %d already exists, set vmid to a free ID or leave it out to use the next free one.\n\n%s", id, err.Error()),
+	)
+	return diags
+}
+
 func getIDToUse(v basetypes.Int64Value, client *pveapi.Client) (id int, err error) {
 	const initialVMID = 100
 
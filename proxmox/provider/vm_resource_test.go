@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,7 +13,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
 	"github.com/mollstam/terraform-provider-proxmox/proxmox/provider/testutil"
 	"github.com/onsi/gomega"
@@ -52,13 +55,16 @@ resource "proxmox_vm" "test" {
 	net = {
 		bridge      = "vmbr0"
 		mac_address = "bc:24:11:6f:9e:d3"
+		firewall    = true
+		rate        = 12.5
+		queues      = 4
 	}
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
 					testCheckVMValuesInPve(&vm, types.StringValue("pve"), types.Int64Value(100), types.StringValue("wall-e"), types.StringValue("Waste Allocation Load Lifter: Earth-Class"), types.Int64Value(2), types.Int64Value(2), types.Int64Value(32)),
-					testCheckVMStorageValuesInPve(ctx, &vm, "virtio0", types.StringValue("local-lvm"), types.Int64Value(30)),
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio0", types.StringValue("local-lvm"), types.StringValue("30G")),
 					testCheckVMNetValuesInPve(ctx, &vm, types.StringValue("vmbr0"), types.StringValue("bc:24:11:6f:9e:d3")),
 					testCheckVMStatusInPve(&vm, "running"),
 					resource.TestCheckResourceAttr("proxmox_vm.test", "node", "pve"),
@@ -75,6 +81,12 @@ resource "proxmox_vm" "test" {
 					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.format", "raw"),
 					resource.TestCheckResourceAttr("proxmox_vm.test", "net.bridge", "vmbr0"),
 					resource.TestCheckResourceAttr("proxmox_vm.test", "net.mac_address", "bc:24:11:6f:9e:d3"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.firewall", "true"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.rate", "12.5"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.queues", "4"),
+					testCheckVMNetOptionInPve(&vm, "firewall", true),
+					testCheckVMNetOptionInPve(&vm, "rate", "12.5"),
+					testCheckVMNetOptionInPve(&vm, "queues", 4),
 					resource.TestCheckNoResourceAttr("proxmox_vm.test", "virtio2"),
 					resource.TestCheckResourceAttr("proxmox_vm.test", "memory", "32"),
 				),
@@ -103,9 +115,9 @@ resource "proxmox_vm" "test" {
 	}
 	
 	net = {
-		name   = "eth0"
 		bridge = "vmbr0"
-    }
+		rate   = 10
+	}
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
@@ -120,6 +132,12 @@ resource "proxmox_vm" "test" {
 					resource.TestCheckResourceAttr("proxmox_vm.test", "sockets", "1"),
 					resource.TestCheckResourceAttr("proxmox_vm.test", "cores", "1"),
 					resource.TestCheckResourceAttr("proxmox_vm.test", "memory", "40"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.firewall", "false"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.rate", "10"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "net.queues"),
+					testCheckVMNetOptionInPve(&vm, "firewall", nil),
+					testCheckVMNetOptionInPve(&vm, "rate", 10),
+					testCheckVMNetOptionInPve(&vm, "queues", nil),
 				),
 			},
 		},
@@ -148,6 +166,8 @@ resource "proxmox_vm" "test" {
 					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "100"),
 					resource.TestCheckNoResourceAttr("proxmox_vm.test", "name"),
 					resource.TestCheckNoResourceAttr("proxmox_vm.test", "description"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "guest_hostname"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "guest_os"),
 					resource.TestCheckResourceAttr("proxmox_vm.test", "status", "running"),
 				),
 			},
@@ -155,6 +175,63 @@ resource "proxmox_vm" "test" {
 	})
 }
 
+func TestAccVMResource_CreateDiskless_RefreshHasNoDiff(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	pxeConfig := providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	boot_order = ["net0"]
+
+	net = {
+		bridge = "vmbr0"
+	}
+}
+`
+	barebonesConfig := providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: pxeConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMConfigValueInPve(&vm, "boot", "order=net0"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.bridge", "vmbr0"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "virtio0"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "ide0"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "disks"),
+				),
+			},
+			{
+				Config:   pxeConfig,
+				PlanOnly: true,
+			},
+			{
+				Config: barebonesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "net"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "virtio0"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "mac_addresses.%"),
+				),
+			},
+			{
+				Config:   barebonesConfig,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
 func TestAccVMResource_CreateTwoVMsWithoutVMID_GetSequentialIds(t *testing.T) {
 	var vma, vmb vmResourceModel
 
@@ -279,6 +356,53 @@ resource "proxmox_vm" "test_b" {
 	})
 }
 
+func TestAccVMResource_CreateOnUnknownNode_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve-does-not-exist"
+	name = "wall-e"
+}
+`,
+				ExpectError: regexp.MustCompile(`There is no node named 'pve-does-not-exist' in the cluster`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_ChangeNodeWithIgnoreNodeDrift_HasEmptyPlan(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	ignore_node_drift = true
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("proxmox_vm.test", "node", "pve"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "ignore_node_drift", "true"),
+				),
+			},
+			{
+				// as if HA had moved the VM from the configured node
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve-other"
+	ignore_node_drift = true
+}
+`,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
 func TestAccVMResource_CreateWithAgent_IpCanBeRead(t *testing.T) {
 	var vm vmResourceModel
 
@@ -323,13 +447,16 @@ resource "proxmox_vm" "test" {
 						}
 						return nil
 					}),
+					resource.TestCheckTypeSetElemAttrPair("proxmox_vm.test", "ip_addresses.*", "proxmox_vm.test", "ipv4_address"),
+					resource.TestCheckResourceAttrSet("proxmox_vm.test", "guest_hostname"),
+					resource.TestCheckResourceAttrSet("proxmox_vm.test", "guest_os"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccVMResource_CreateAndUpdateStopped(t *testing.T) {
+func TestAccVMResource_CreateWithAgent_NetworkInterfacesCanBeRead(t *testing.T) {
 	var vm vmResourceModel
 
 	ctx := testutil.GetTestLoggingContext()
@@ -341,34 +468,114 @@ func TestAccVMResource_CreateAndUpdateStopped(t *testing.T) {
 				Config: providerConfig + `
 resource "proxmox_vm" "test" {
 	node = "pve"
-	name = "wall-e"
-	status = "stopped"
+
+	agent = true
+	clone = 300
+
+	memory = 2048
+
+	net = {
+		bridge = "vnet0"
+	}
+	ipconfig0 = "ip=dhcp"
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
-					testCheckVMStatusInPve(&vm, "stopped"),
+					resource.TestMatchResourceAttr("proxmox_vm.test", "ipv4_address", regexp.MustCompile(`^10\.0\.0\.`)),
+					resource.TestCheckResourceAttrWith("proxmox_vm.test", "network_interfaces.#", func(value string) error {
+						if value == "" || value == "0" {
+							return errors.New("Expected network_interfaces to be populated")
+						}
+						return nil
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("proxmox_vm.test", "network_interfaces.*", map[string]string{
+						"name": "eth0",
+					}),
+					resource.TestCheckTypeSetElemAttrPair("proxmox_vm.test", "network_interfaces.*.ip_addresses.*", "proxmox_vm.test", "ipv4_address"),
 				),
 			},
-			{
-				Config: providerConfig + `
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithAgent_VirtualInterfacesAreExcluded(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	config := func(exclude string) string {
+		return providerConfig + `
 resource "proxmox_vm" "test" {
 	node = "pve"
-	name = "wall-e"
-	status = "running"
+
+	agent = true
+	clone = 300
+
+	memory = 2048
+
+	net = {
+		bridge = "vnet0"
+	}
+	ipconfig0 = "ip=dhcp"
+` + exclude + `
 }
-`,
+`
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(""),
 				Check: resource.ComposeTestCheckFunc(
 					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
-					testCheckVMStatusInPve(&vm, "running"),
-					resource.TestCheckResourceAttr("proxmox_vm.test", "status", "running"),
+					resource.TestMatchResourceAttr("proxmox_vm.test", "ipv4_address", regexp.MustCompile(`^10\.0\.0\.`)),
+					resource.TestCheckTypeSetElemAttr("proxmox_vm.test", "agent_exclude_interfaces.*", "lo"),
+					resource.TestCheckTypeSetElemAttr("proxmox_vm.test", "agent_exclude_interfaces.*", "docker*"),
+					resource.TestCheckTypeSetElemNestedAttrs("proxmox_vm.test", "network_interfaces.*", map[string]string{
+						"name": "eth0",
+					}),
+					testCheckNoAgentInterface("proxmox_vm.test", "lo"),
+				),
+			},
+			{
+				Config: config(`	agent_exclude_interfaces = []`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("proxmox_vm.test", "agent_exclude_interfaces.#", "0"),
+					resource.TestCheckTypeSetElemNestedAttrs("proxmox_vm.test", "network_interfaces.*", map[string]string{
+						"name": "lo",
+					}),
 				),
 			},
 		},
 	})
 }
 
-func TestAccVMResource_RefreshOutOfBandDestroyedVM_SucceedsWithNonEmptyPlan(t *testing.T) {
+// testCheckNoAgentInterface checks that no interface called name is among the network_interfaces of
+// the VM.
+func testCheckNoAgentInterface(resourceName string, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("Not found: %s", resourceName)
+		}
+
+		for k, v := range rs.Primary.Attributes {
+			if strings.HasPrefix(k, "network_interfaces.") && strings.HasSuffix(k, ".name") && v == name {
+				return fmt.Errorf("Expected interface %s to be excluded from network_interfaces", name)
+			}
+		}
+		return nil
+	}
+}
+
+func TestAccVMResource_CreateWithAgent_IpOfSecondNetCanBeRead(t *testing.T) {
 	var vm vmResourceModel
 
 	ctx := testutil.GetTestLoggingContext()
@@ -380,23 +587,58 @@ func TestAccVMResource_RefreshOutOfBandDestroyedVM_SucceedsWithNonEmptyPlan(t *t
 				Config: providerConfig + `
 resource "proxmox_vm" "test" {
 	node = "pve"
-	name = "wall-e"
+
+	agent           = true
+	agent_interface = 1
+	clone           = 300
+
+	memory = 2048
+
+	net = {
+		bridge = "vmbr1"
+	}
+	net1 = {
+		bridge = "vnet0"
+	}
+	ipconfig1 = "ip=dhcp"
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "agent_interface", "1"),
+					resource.TestMatchResourceAttr("proxmox_vm.test", "ipv4_address", regexp.MustCompile(`^10\.0\.0\.`)),
+					resource.TestCheckTypeSetElemAttrPair("proxmox_vm.test", "ip_addresses.*", "proxmox_vm.test", "ipv4_address"),
 				),
 			},
+		},
+	})
+}
+
+func TestAccVMResource_AgentInterfaceWithoutNet_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
 			{
-				RefreshState:       true,
-				PreConfig:          destroyVMInPve(&vm),
-				ExpectNonEmptyPlan: true,
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	agent           = true
+	agent_interface = 1
+
+	net = {
+		bridge = "vmbr0"
+	}
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`agent_interface is 1 but net1 isn't configured on the VM`),
 			},
 		},
 	})
 }
 
-func TestAccVMResource_ApplyOutOfBandDestroyedVM_IsRecreated(t *testing.T) {
+func TestAccVMResource_CreateAndUpdateStopped(t *testing.T) {
 	var vm vmResourceModel
 
 	ctx := testutil.GetTestLoggingContext()
@@ -409,29 +651,33 @@ func TestAccVMResource_ApplyOutOfBandDestroyedVM_IsRecreated(t *testing.T) {
 resource "proxmox_vm" "test" {
 	node = "pve"
 	name = "wall-e"
+	status = "stopped"
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStatusInPve(&vm, "stopped"),
 				),
 			},
 			{
-				PreConfig: destroyVMInPve(&vm),
 				Config: providerConfig + `
 resource "proxmox_vm" "test" {
 	node = "pve"
 	name = "wall-e"
+	status = "running"
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStatusInPve(&vm, "running"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "status", "running"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccVMResource_ApplyOutOfBandModified_IsReconciledToPlan(t *testing.T) {
+func TestAccVMResource_StopWithShutdownMode(t *testing.T) {
 	var vm vmResourceModel
 
 	ctx := testutil.GetTestLoggingContext()
@@ -444,93 +690,2583 @@ func TestAccVMResource_ApplyOutOfBandModified_IsReconciledToPlan(t *testing.T) {
 resource "proxmox_vm" "test" {
 	node = "pve"
 	name = "wall-e"
-
-	sockets = 1
-	cores = 4
-	memory = 36
+	stop_mode = "shutdown"
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStatusInPve(&vm, "running"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "stop_mode", "shutdown"),
 				),
 			},
 			{
-				PreConfig: testutil.ComposeFunc(
-					setVMMemoryInPve(&vm, 30),
-					setVMSocketsInPve(&vm, 2),
-					setVMCoresInPve(&vm, 2),
-				),
+				// nothing installed that reacts to ACPI, so this waits out the shutdown and falls back to stopping
 				Config: providerConfig + `
 resource "proxmox_vm" "test" {
 	node = "pve"
 	name = "wall-e"
-
-	sockets = 1
-	cores = 4
-	memory = 36
+	stop_mode = "shutdown"
+	status = "stopped"
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
-					testCheckVMValuesInPve(&vm, types.StringValue("pve"), types.Int64Value(100), types.StringValue("wall-e"), types.StringNull(), types.Int64Value(1), types.Int64Value(4), types.Int64Value(36)),
-					resource.TestCheckResourceAttr("proxmox_vm.test", "memory", "36"),
+					testCheckVMStatusInPve(&vm, "stopped"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "status", "stopped"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccVMResource_CreateCloneOfTemplate(t *testing.T) {
+func TestAccVMResource_InvalidStopMode_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "wall-e"
+	stop_mode = "halt"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateAndUpdateDiskOptions(t *testing.T) {
 	var vm vmResourceModel
 
 	ctx := testutil.GetTestLoggingContext()
 
-	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
-	if err != nil {
-		t.Error("Error during setup: " + err.Error())
-		return
-	}
-	cleanUpFunc := destroyVMInPve(template)
-	defer cleanUpFunc()
-
 	resource.Test(t, resource.TestCase{
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
 			{
 				Config: providerConfig + `
-resource "proxmox_vm" "test_clone" {
+resource "proxmox_vm" "test" {
 	node = "pve"
-	name = "m-o"
-	description = "Microbe-Obliterator"
-	
-	clone = "200"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 5
+		storage = "local-lvm"
+	}
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
-					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
-					testCheckVMValuesInPve(&vm, types.StringValue("pve"), types.Int64Value(100), types.StringValue("m-o"), types.StringValue("Microbe-Obliterator"), types.Int64Value(1), types.Int64Value(1), types.Int64Value(16)),
-					testCheckVMStatusInPve(&vm, "running"),
-					testCheckVMIsCloneOf(&vm, template),
-					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "node", "pve"),
-					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "vmid", "100"),
-					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "name", "m-o"),
-					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "description", "Microbe-Obliterator"),
-					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "status", "running"),
-					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "clone", "200"),
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "100"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "virtio0.cache"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "virtio0.aio"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.discard", "false"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.iothread", "false"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.backup", "true"),
 				),
 			},
 			{
 				Config: providerConfig + `
-resource "proxmox_vm" "test_clone" {
+resource "proxmox_vm" "test" {
 	node = "pve"
-	name = "m-o"
-	description = "Microbe-Obliterator"
-	
-	clone = "200"
-}
-`,
-				Check: resource.ComposeTestCheckFunc(
-					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
+
+	virtio0 = {
+		media   = "disk"
+		size    = 5
+		storage = "local-lvm"
+		cache   = "writeback"
+		discard = true
+		iothread = true
+		backup = false
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "100"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.cache", "writeback"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.discard", "true"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.iothread", "true"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.backup", "false"),
+					testCheckVMVirtioBackupInPve(ctx, &vm, false),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 5
+		storage = "local-lvm"
+		cache   = "none"
+		aio     = "native"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.cache", "none"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.aio", "native"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_InvalidAIO_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 5
+		storage = "local-lvm"
+		aio     = "posix"
+	}
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_GrowAndShrinkDisk(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 30
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio0", types.StringValue("local-lvm"), types.StringValue("30G")),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 40
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio0", types.StringValue("local-lvm"), types.StringValue("40G")),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.size", "40"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 35
+		storage = "local-lvm"
+	}
+}
+`,
+				ExpectError: regexp.MustCompile(`Disk Shrink Not Supported`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithCloudinitDrive(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 5
+		storage = "local-lvm"
+	}
+
+	ide2 = {
+		media   = "cloudinit"
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "ide2.media", "cloudinit"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "ide2.storage", "local-lvm"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "ide2.file"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithCloudinitDriveWithoutStorage_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ide2 = {
+		media = "cloudinit"
+	}
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Missing Cloud-Init Storage`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateAndUpdateCloudinitUser(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ciuser     = "wall-e"
+	cipassword = "hunter2"
+
+	ide2 = {
+		media   = "cloudinit"
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "ciuser", "wall-e"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "cipassword", "hunter2"),
+					testCheckVMConfigValueInPve(&vm, "ciuser", "wall-e"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ciuser     = "eve"
+	cipassword = "hunter2"
+
+	ide2 = {
+		media   = "cloudinit"
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "100"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "ciuser", "eve"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "cipassword", "hunter2"),
+					testCheckVMConfigValueInPve(&vm, "ciuser", "eve"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ide2 = {
+		media   = "cloudinit"
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "100"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "ciuser"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "cipassword"),
+					testCheckVMConfigKeyNotInPve(&vm, "ciuser"),
+					testCheckVMConfigKeyNotInPve(&vm, "cipassword"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateAndUpdateCloudinitSSHKeysAndDNS(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	sshkeys = <<EOT
+ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQDfnHfHWUoXXyGPgjFLwH8SE3MozO90AAQI9A338Bm0Srn6SJkdlOyaQdLXbvkTv1UTLhiDUR2KIsyNALYzpq5wNWirbMa8+8eBElrQwNwDP1WNdRW63lL4C01mdqMavqLiYoycOJjpOe7EmDgnNixIPesjBwPx5tHELJdHiLrU6Q== walle@test
+ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQCsPEwRz7XqtM0cIr9YtokMt6q7pt8Mz4h+nh+KC0WD163Puc5JZ0S9ZGcPX7fHObmXRquBZ1Ek4cBmi4SnY1V4/9bNWvDttFUVVhAwuLWJzf+pGyRnUZxl8VIwdLzGZvX6h0NWfwEIwjDyRZZW1VE/dlwyVTxUYwv2IhF8pdycNQ== eve@test
+EOT
+
+	nameserver   = "192.168.1.1"
+	searchdomain = "example.com"
+
+	ide2 = {
+		media   = "cloudinit"
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "sshkeys", "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQDfnHfHWUoXXyGPgjFLwH8SE3MozO90AAQI9A338Bm0Srn6SJkdlOyaQdLXbvkTv1UTLhiDUR2KIsyNALYzpq5wNWirbMa8+8eBElrQwNwDP1WNdRW63lL4C01mdqMavqLiYoycOJjpOe7EmDgnNixIPesjBwPx5tHELJdHiLrU6Q== walle@test\nssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQCsPEwRz7XqtM0cIr9YtokMt6q7pt8Mz4h+nh+KC0WD163Puc5JZ0S9ZGcPX7fHObmXRquBZ1Ek4cBmi4SnY1V4/9bNWvDttFUVVhAwuLWJzf+pGyRnUZxl8VIwdLzGZvX6h0NWfwEIwjDyRZZW1VE/dlwyVTxUYwv2IhF8pdycNQ== eve@test\n"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "nameserver", "192.168.1.1"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "searchdomain", "example.com"),
+					testCheckVMSSHKeysInPve(&vm, "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQDfnHfHWUoXXyGPgjFLwH8SE3MozO90AAQI9A338Bm0Srn6SJkdlOyaQdLXbvkTv1UTLhiDUR2KIsyNALYzpq5wNWirbMa8+8eBElrQwNwDP1WNdRW63lL4C01mdqMavqLiYoycOJjpOe7EmDgnNixIPesjBwPx5tHELJdHiLrU6Q== walle@test\nssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQCsPEwRz7XqtM0cIr9YtokMt6q7pt8Mz4h+nh+KC0WD163Puc5JZ0S9ZGcPX7fHObmXRquBZ1Ek4cBmi4SnY1V4/9bNWvDttFUVVhAwuLWJzf+pGyRnUZxl8VIwdLzGZvX6h0NWfwEIwjDyRZZW1VE/dlwyVTxUYwv2IhF8pdycNQ== eve@test"),
+					testCheckVMConfigValueInPve(&vm, "nameserver", "192.168.1.1"),
+					testCheckVMConfigValueInPve(&vm, "searchdomain", "example.com"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	sshkeys = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQCsPEwRz7XqtM0cIr9YtokMt6q7pt8Mz4h+nh+KC0WD163Puc5JZ0S9ZGcPX7fHObmXRquBZ1Ek4cBmi4SnY1V4/9bNWvDttFUVVhAwuLWJzf+pGyRnUZxl8VIwdLzGZvX6h0NWfwEIwjDyRZZW1VE/dlwyVTxUYwv2IhF8pdycNQ== eve@test"
+
+	nameserver   = "192.168.1.2"
+	searchdomain = "example.org"
+
+	ide2 = {
+		media   = "cloudinit"
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "100"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "sshkeys", "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQCsPEwRz7XqtM0cIr9YtokMt6q7pt8Mz4h+nh+KC0WD163Puc5JZ0S9ZGcPX7fHObmXRquBZ1Ek4cBmi4SnY1V4/9bNWvDttFUVVhAwuLWJzf+pGyRnUZxl8VIwdLzGZvX6h0NWfwEIwjDyRZZW1VE/dlwyVTxUYwv2IhF8pdycNQ== eve@test"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "nameserver", "192.168.1.2"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "searchdomain", "example.org"),
+					testCheckVMSSHKeysInPve(&vm, "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQCsPEwRz7XqtM0cIr9YtokMt6q7pt8Mz4h+nh+KC0WD163Puc5JZ0S9ZGcPX7fHObmXRquBZ1Ek4cBmi4SnY1V4/9bNWvDttFUVVhAwuLWJzf+pGyRnUZxl8VIwdLzGZvX6h0NWfwEIwjDyRZZW1VE/dlwyVTxUYwv2IhF8pdycNQ== eve@test"),
+					testCheckVMConfigValueInPve(&vm, "nameserver", "192.168.1.2"),
+					testCheckVMConfigValueInPve(&vm, "searchdomain", "example.org"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ide2 = {
+		media   = "cloudinit"
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "100"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "sshkeys"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "nameserver"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "searchdomain"),
+					testCheckVMConfigKeyNotInPve(&vm, "sshkeys"),
+					testCheckVMConfigKeyNotInPve(&vm, "nameserver"),
+					testCheckVMConfigKeyNotInPve(&vm, "searchdomain"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateAndUpdateIpconfig(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ipconfig0 = "ip=10.0.0.5/24,gw=10.0.0.1"
+
+	net = {
+		bridge = "vmbr0"
+	}
+
+	ide2 = {
+		media   = "cloudinit"
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "ipconfig0", "ip=10.0.0.5/24,gw=10.0.0.1"),
+					testCheckVMConfigValueInPve(&vm, "ipconfig0", "ip=10.0.0.5/24,gw=10.0.0.1"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ipconfig0 = "ip=dhcp"
+
+	net = {
+		bridge = "vmbr0"
+	}
+
+	ide2 = {
+		media   = "cloudinit"
+		storage = "local-lvm"
+	}
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "100"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "ipconfig0", "ip=dhcp"),
+					testCheckVMConfigValueInPve(&vm, "ipconfig0", "ip=dhcp"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ipconfig0 = "ip=dhcp,ip6=2001:db8::5/64,gw6=2001:db8::1"
+
+	net = {
+		bridge = "vmbr0"
+	}
+
+	ide2 = {
+		media   = "cloudinit"
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "ipconfig0", "ip=dhcp,ip6=2001:db8::5/64,gw6=2001:db8::1"),
+					testCheckVMConfigValueInPve(&vm, "ipconfig0", "ip=dhcp,ip6=2001:db8::5/64,gw6=2001:db8::1"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	net = {
+		bridge = "vmbr0"
+	}
+
+	ide2 = {
+		media   = "cloudinit"
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "ipconfig0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithInvalidIpconfig_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ipconfig0 = "ip=10.0.0.300/24"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ipconfig0 = "ip6=foo"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ipconfig0 = "ip6=2001:db8::5/64,gw6=10.0.0.1"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_EjectAndRemoveCdrom(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ide2 = {
+		media = "cdrom"
+		file  = "local:iso/ubuntu-22.04.4-live-server-amd64.iso"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "ide2.file", "local:iso/ubuntu-22.04.4-live-server-amd64.iso"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	ide2 = {
+		media = "cdrom"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "ide2.media", "cdrom"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "ide2.file"),
+					testCheckVMConfigValueInPve(&vm, "ide2", "none,media=cdrom"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "ide2"),
+					testCheckVMConfigKeyNotInPve(&vm, "ide2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateAndUpdateDisksList(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	disks = [
+		{
+			interface = "virtio1"
+			media     = "disk"
+			size      = "2G"
+			storage   = "local-lvm"
+		},
+		{
+			interface = "virtio0"
+			media     = "disk"
+			size      = "1G"
+			storage   = "local-lvm"
+		},
+	]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio0", types.StringValue("local-lvm"), types.StringValue("1G")),
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio1", types.StringValue("local-lvm"), types.StringValue("2G")),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "disks.#", "2"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "disks.0.interface", "virtio1"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "disks.1.interface", "virtio0"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "virtio0"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	disks = [
+		{
+			interface = "virtio1"
+			media     = "disk"
+			size      = "3G"
+			storage   = "local-lvm"
+		},
+		{
+			interface = "virtio0"
+			media     = "disk"
+			size      = "1G"
+			storage   = "local-lvm"
+		},
+	]
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio1", types.StringValue("local-lvm"), types.StringValue("3G")),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "disks.0.size", "3G"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_DisksListWithVirtioSlot_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	disks = [
+		{
+			interface = "virtio1"
+			media     = "disk"
+			size      = "1G"
+			storage   = "local-lvm"
+		},
+	]
+
+	virtio0 = {
+		media   = "disk"
+		size    = "1G"
+		storage = "local-lvm"
+	}
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_DiskSizeInMBAndGB(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = "512M"
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio0", types.StringValue("local-lvm"), types.StringValue("512M")),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.size", "512M"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = "1G"
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio0", types.StringValue("local-lvm"), types.StringValue("1G")),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.size", "1G"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 2
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio0", types.StringValue("local-lvm"), types.StringValue("2G")),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.size", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_MoveDiskToOtherStorage(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 5
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio0", types.StringValue("local-lvm"), types.StringValue("5G")),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 5
+		storage = "local"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio0", types.StringValue("local"), types.StringValue("5G")),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.storage", "local"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_DiskWithNonWholeGBSize_HasEmptyPlan(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 5
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+				),
+			},
+			{
+				// disk is now 5.5G, like a cloud image would be, and read back exactly
+				PreConfig:    resizeVMDiskInPve(&vm, "virtio0", "+512M"),
+				RefreshState: true,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("proxmox_vm.test", "virtio0.size", "5632M"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	virtio0 = {
+		media   = "disk"
+		size    = "5632M"
+		storage = "local-lvm"
+	}
+}
+`,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_RefreshOutOfBandDestroyedVM_SucceedsWithNonEmptyPlan(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "wall-e"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+				),
+			},
+			{
+				RefreshState:       true,
+				PreConfig:          destroyVMInPve(&vm),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_ApplyOutOfBandDestroyedVM_IsRecreated(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "wall-e"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+				),
+			},
+			{
+				PreConfig: destroyVMInPve(&vm),
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "wall-e"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_Rename_IsUpdatedInPlace(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "wall-e"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "name", "wall-e"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "eve"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMValuesInPve(&vm, types.StringValue("pve"), types.Int64Value(100), types.StringValue("eve"), types.StringNull(), types.Int64Value(1), types.Int64Value(1), types.Int64Value(16)),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "name", "eve"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_ApplyOutOfBandModified_IsReconciledToPlan(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "wall-e"
+
+	sockets = 1
+	cores = 4
+	memory = 36
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+				),
+			},
+			{
+				PreConfig: testutil.ComposeFunc(
+					setVMMemoryInPve(&vm, 30),
+					setVMSocketsInPve(&vm, 2),
+					setVMCoresInPve(&vm, 2),
+				),
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "wall-e"
+
+	sockets = 1
+	cores = 4
+	memory = 36
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMValuesInPve(&vm, types.StringValue("pve"), types.Int64Value(100), types.StringValue("wall-e"), types.StringNull(), types.Int64Value(1), types.Int64Value(4), types.Int64Value(36)),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "memory", "36"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_UpdateWithUnmanagedNetOption_IsKept(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	memory = 16
+
+	net = {
+		bridge = "vmbr0"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+				),
+			},
+			{
+				PreConfig: setVMNetOptionInPve(&vm, "mtu", "1400"),
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	memory = 32
+
+	net = {
+		bridge = "vmbr0"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "memory", "32"),
+					testCheckVMNetOptionInPve(&vm, "mtu", 1400),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithTwoNets(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	net = {
+		bridge = "vmbr0"
+	}
+	net1 = {
+		bridge = "vmbr1"
+		model  = "e1000"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.bridge", "vmbr0"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net1.bridge", "vmbr1"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net1.model", "e1000"),
+					resource.TestCheckResourceAttrSet("proxmox_vm.test", "net1.mac_address"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "net2"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "mac_addresses.%", "2"),
+					resource.TestCheckResourceAttrPair("proxmox_vm.test", "mac_addresses.net0", "proxmox_vm.test", "net.mac_address"),
+					resource.TestCheckResourceAttrPair("proxmox_vm.test", "mac_addresses.net1", "proxmox_vm.test", "net1.mac_address"),
+					testCheckVMNetOptionInPve(&vm, "bridge", "vmbr0"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	net = {
+		bridge = "vmbr0"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.bridge", "vmbr0"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "net1"),
+					testCheckVMConfigKeyNotInPve(&vm, "net1"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "mac_addresses.%", "1"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "mac_addresses.net1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithUnknownBridge_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	net = {
+		bridge = "vmbr0"
+	}
+	net1 = {
+		bridge = "vmbr9"
+	}
+}
+`,
+				ExpectError: regexp.MustCompile(`The bridge vmbr9 does not exist on node 'pve'`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateAndUpdateNetTag(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	net = {
+		bridge = "vmbr0"
+		tag    = 100
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.tag", "100"),
+					testCheckVMNetOptionInPve(&vm, "tag", 100),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	net = {
+		bridge = "vmbr0"
+		tag    = 200
+	}
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.tag", "200"),
+					testCheckVMNetOptionInPve(&vm, "tag", 200),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	net = {
+		bridge = "vmbr0"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "net.tag"),
+					testCheckVMNetOptionInPve(&vm, "tag", nil),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateAndUpdateNetLinkDown(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	net = {
+		bridge    = "vmbr0"
+		link_down = true
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.link_down", "true"),
+					testCheckVMNetOptionInPve(&vm, "bridge", "vmbr0"),
+					testCheckVMNetOptionInPve(&vm, "link_down", true),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	net = {
+		bridge = "vmbr0"
+	}
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.link_down", "false"),
+					testCheckVMNetOptionInPve(&vm, "link_down", nil),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithOnboot_RefreshHasNoDiff(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	config := providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	onboot  = true
+	startup = "order=2,up=30"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "onboot", "true"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "startup", "order=2,up=30"),
+					testCheckVMConfigValueInPve(&vm, "onboot", float64(1)),
+					testCheckVMConfigValueInPve(&vm, "startup", "order=2,up=30"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "onboot", "false"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "startup"),
+					testCheckVMConfigValueInPve(&vm, "onboot", float64(0)),
+					testCheckVMConfigKeyNotInPve(&vm, "startup"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_Protection_PreventsDestroy(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	config := providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	protection = true
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "protection", "true"),
+					testCheckVMConfigValueInPve(&vm, "protection", float64(1)),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+			{
+				Config:      config,
+				Destroy:     true,
+				ExpectError: regexp.MustCompile(`VM \d+ has protection enabled`),
+			},
+			{
+				// allow the test to clean up after itself
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "protection", "false"),
+					testCheckVMConfigValueInPve(&vm, "protection", float64(0)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_Import(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	config := providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "eve"
+
+	cores  = 2
+	memory = 32
+	onboot = true
+
+	virtio0 = {
+		media   = "disk"
+		size    = 5
+		storage = "local-lvm"
+	}
+
+	net = {
+		bridge = "vmbr0"
+	}
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+				),
+			},
+			{
+				Config:            config,
+				ResourceName:      "proxmox_vm.test",
+				ImportState:       true,
+				ImportStateId:     "pve/100",
+				ImportStateVerify: true,
+			},
+			{
+				Config:            config,
+				ResourceName:      "proxmox_vm.test",
+				ImportState:       true,
+				ImportStateId:     "100",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_ImportWithInvalidId_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+}
+`,
+				ResourceName:  "proxmox_vm.test",
+				ImportState:   true,
+				ImportStateId: "pve/not-a-vmid",
+				ExpectError:   regexp.MustCompile(`Unexpected Import Identifier`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_ImportLXC_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	vmid       = 100
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+`,
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	vmid       = 100
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+
+resource "proxmox_vm" "test" {
+	node = "pve"
+}
+`,
+				ResourceName:  "proxmox_vm.test",
+				ImportState:   true,
+				ImportStateId: "pve/100",
+				ExpectError:   regexp.MustCompile(`Guest 100 is not a VM`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_UpdateStartupOrder(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	startup = "order=1"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "onboot", "false"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "startup", "order=1"),
+					testCheckVMConfigValueInPve(&vm, "startup", "order=1"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	onboot  = true
+	startup = "order=2,up=30,down=60"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "onboot", "true"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "startup", "order=2,up=30,down=60"),
+					testCheckVMConfigValueInPve(&vm, "onboot", float64(1)),
+					testCheckVMConfigValueInPve(&vm, "startup", "order=2,up=30,down=60"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_InvalidStartup_Error(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	startup = "order=first,up=30"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateAndUpdateCPU(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "cpu"),
+					testCheckVMConfigKeyNotInPve(&vm, "cpu"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	cpu  = "host"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "cpu", "host"),
+					testCheckVMConfigValueInPve(&vm, "cpu", "host"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	cpu  = "x86-64-v2-AES"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "cpu", "x86-64-v2-AES"),
+					testCheckVMConfigValueInPve(&vm, "cpu", "x86-64-v2-AES"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "cpu"),
+					testCheckVMConfigKeyNotInPve(&vm, "cpu"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateAndUpdateNumaAndVcpus(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node    = "pve"
+	sockets = 2
+	cores   = 2
+	numa    = true
+	vcpus   = 2
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "numa", "true"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vcpus", "2"),
+					testCheckVMConfigValueInPve(&vm, "numa", float64(1)),
+					testCheckVMConfigValueInPve(&vm, "vcpus", float64(2)),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node    = "pve"
+	sockets = 2
+	cores   = 2
+	vcpus   = 3
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "numa", "false"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vcpus", "3"),
+					testCheckVMConfigValueInPve(&vm, "numa", float64(0)),
+					testCheckVMConfigValueInPve(&vm, "vcpus", float64(3)),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node    = "pve"
+	sockets = 2
+	cores   = 2
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "vcpus"),
+					testCheckVMConfigKeyNotInPve(&vm, "vcpus"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithTooManyVcpus_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node    = "pve"
+	sockets = 1
+	cores   = 2
+	vcpus   = 4
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`vcpus \(4\) can't exceed sockets \* cores \(2\)`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithoutNode_UsesDefaultNode(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfigWithDefaultNode + `
+resource "proxmox_vm" "test" {
+	name = "default-node"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "node", "pve"),
+				),
+			},
+			{
+				// setting node explicitly to the same node is not a change
+				Config: providerConfigWithDefaultNode + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "default-node"
+}
+`,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithoutNodeOrDefaultNode_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	name = "no-node"
+}
+`,
+				ExpectError: regexp.MustCompile(`Missing Node`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateError_NamesVMAndNode(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "nonexistent"
+	vmid = 150
+}
+`,
+				ExpectError: regexp.MustCompile(`VM 150 on node nonexistent: There is no node named 'nonexistent'`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_UnknownDefaultNode_OnlyFailsResourcesUsingIt(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	config := strings.Replace(providerConfigWithDefaultNode, `default_node = "pve"`, `default_node = "nonexistent"`, 1)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// a bad default_node is only a warning when configuring the provider
+				Config: config + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "explicit-node"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+				),
+			},
+			{
+				Config: config + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "explicit-node"
+}
+
+resource "proxmox_vm" "test2" {
+	name = "default-node"
+}
+`,
+				ExpectError: regexp.MustCompile(`Node Not Found`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_UpdateDescription_DoesNotReboot(t *testing.T) {
+	var vm vmResourceModel
+	var pid any
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node        = "pve"
+	description = "before"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStatusInPve(&vm, stateRunning),
+					testCheckVMPidInPve(&vm, &pid),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node        = "pve"
+	description = "after"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "description", "after"),
+					testCheckVMConfigValueInPve(&vm, "description", "after"),
+					testCheckVMPidInPve(&vm, &pid),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_UpdateRequiringReboot_IsRebooted(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	agent = true
+	clone = 300
+
+	memory = 2048
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStatusInPve(&vm, stateRunning),
+				),
+			},
+			{
+				// the CPU type can't be changed on a running VM, it's left pending until rebooted
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	agent = true
+	clone = 300
+
+	memory = 2048
+	cpu    = "host"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMStatusInPve(&vm, stateRunning),
+					testCheckVMConfigValueInPve(&vm, "cpu", "host"),
+					testCheckVMRebootedInPve(&vm),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateAndUpdatePool(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	for _, poolid := range []string{"tf-test-a", "tf-test-b"} {
+		cleanUpFunc, err := createPoolInPve(poolid)
+		if err != nil {
+			t.Error("Error during setup: " + err.Error())
+			return
+		}
+		defer cleanUpFunc()
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	pool = "tf-test-a"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "pool", "tf-test-a"),
+					testCheckGuestPoolInPve(&vm.VMID, "tf-test-a"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	pool = "tf-test-b"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "pool", "tf-test-b"),
+					testCheckGuestPoolInPve(&vm.VMID, "tf-test-b"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "pool"),
+					testCheckGuestPoolInPve(&vm.VMID, ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CloneIntoPool(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	cleanUpPoolFunc, err := createPoolInPve("tf-test-a")
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	defer cleanUpPoolFunc()
+
+	config := providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node       = "pve"
+	clone      = "200"
+	clone_full = false
+	pool       = "tf-test-a"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
+					testCheckVMIsCloneOf(&vm, template),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "pool", "tf-test-a"),
+					testCheckGuestPoolInPve(&vm.VMID, "tf-test-a"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CloneIntoPoolWithTags(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	cleanUpPoolFunc, err := createPoolInPve("tf-test-a")
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	defer cleanUpPoolFunc()
+
+	config := providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node        = "pve"
+	clone       = "200"
+	clone_full  = false
+	pool        = "tf-test-a"
+	tags        = ["web", "prod"]
+	description = "Waste Allocation Load Lifter"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
+					testCheckVMIsCloneOf(&vm, template),
+					testCheckGuestPoolInPve(&vm.VMID, "tf-test-a"),
+					testCheckVMConfigValueInPve(&vm, "tags", "prod;web"),
+					testCheckVMConfigValueInPve(&vm, "description", "Waste Allocation Load Lifter"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateAndUpdateTags(t *testing.T) {
+	var vm vmResourceModel
+	var pid any
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	tags = ["web", "prod"]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "tags.#", "2"),
+					resource.TestCheckTypeSetElemAttr("proxmox_vm.test", "tags.*", "prod"),
+					resource.TestCheckTypeSetElemAttr("proxmox_vm.test", "tags.*", "web"),
+					testCheckVMConfigValueInPve(&vm, "tags", "prod;web"),
+					testCheckVMPidInPve(&vm, &pid),
+				),
+			},
+			{
+				// the order tags are given in doesn't matter
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	tags = ["prod", "web"]
+}
+`,
+				PlanOnly: true,
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	tags = ["prod", "web", "db"]
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "tags.#", "3"),
+					testCheckVMConfigValueInPve(&vm, "tags", "db;prod;web"),
+					// tags are cosmetic, the VM is not rebooted
+					testCheckVMPidInPve(&vm, &pid),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "tags.#"),
+					testCheckVMConfigKeyNotInPve(&vm, "tags"),
+					testCheckVMPidInPve(&vm, &pid),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_InvalidTag_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	tags = ["not a tag"]
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_InvalidName_CausesError(t *testing.T) {
+	for _, name := range []string{"wall_e", "-wall-e", "wall-e.", "wall e"} {
+		resource.Test(t, resource.TestCase{
+			ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+			Steps: []resource.TestStep{
+				{
+					Config: providerConfig + fmt.Sprintf(`
+resource "proxmox_vm" "test" {
+	node = "pve"
+	name = "%s"
+}
+`, name),
+					ExpectError: regexp.MustCompile(`Invalid Attribute Value Match`),
+				},
+			},
+		})
+	}
+}
+
+func TestAccVMResource_CreateAndUpdateBalloon(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node    = "pve"
+	memory  = 2048
+	balloon = 1024
+	shares  = 500
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "memory", "2048"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "balloon", "1024"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "shares", "500"),
+					testCheckVMConfigValueInPve(&vm, "balloon", float64(1024)),
+					testCheckVMConfigValueInPve(&vm, "shares", float64(500)),
+				),
+			},
+			{
+				// refreshing a ballooned VM must not produce a diff
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node    = "pve"
+	memory  = 2048
+	balloon = 1024
+	shares  = 500
+}
+`,
+				PlanOnly: true,
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node    = "pve"
+	memory  = 2048
+	balloon = 0
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "balloon", "0"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "shares"),
+					testCheckVMConfigValueInPve(&vm, "balloon", float64(0)),
+					testCheckVMConfigKeyNotInPve(&vm, "shares"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node   = "pve"
+	memory = 2048
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "balloon"),
+					testCheckVMConfigKeyNotInPve(&vm, "balloon"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithSharesWithoutBallooning_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node    = "pve"
+	memory  = 1024
+	balloon = 0
+	shares  = 500
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Shares`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithBalloonAboveMemory_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node    = "pve"
+	memory  = 1024
+	balloon = 2048
+}
+`,
+				ExpectError: regexp.MustCompile(`balloon \(2048\) can't exceed memory \(1024\)`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateOVMFWithEFIDisk(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	bios = "ovmf"
+
+	efidisk = {
+		storage           = "local-lvm"
+		pre_enrolled_keys = true
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "bios", "ovmf"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "efidisk.storage", "local-lvm"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "efidisk.efitype", "4m"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "efidisk.pre_enrolled_keys", "true"),
+					testCheckVMConfigValueInPve(&vm, "bios", "ovmf"),
+				),
+			},
+			{
+				// changing something else must leave the efidisk alone
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node   = "pve"
+	bios   = "ovmf"
+	memory = 32
+
+	efidisk = {
+		storage           = "local-lvm"
+		pre_enrolled_keys = true
+	}
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMConfigKeyNotInPve(&vm, "unused0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateOVMFWithoutEFIDisk_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	bios = "ovmf"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`bios ovmf requires an efidisk`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateAndUpdateBootOrder(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	boot_order = ["virtio0", "net0"]
+
+	net = {
+		bridge = "vmbr0"
+	}
+	virtio0 = {
+		media   = "disk"
+		size    = 1
+		storage = "local-lvm"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "boot_order.#", "2"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "boot_order.0", "virtio0"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "boot_order.1", "net0"),
+					testCheckVMConfigValueInPve(&vm, "boot", "order=virtio0;net0"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	boot_order = ["net0", "virtio0"]
+
+	net = {
+		bridge = "vmbr0"
+	}
+	virtio0 = {
+		media   = "disk"
+		size    = 1
+		storage = "local-lvm"
+	}
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "boot_order.0", "net0"),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "boot_order.1", "virtio0"),
+					testCheckVMConfigValueInPve(&vm, "boot", "order=net0;virtio0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateWithBootOrderOfMissingDevice_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	boot_order = ["virtio0"]
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`boot_order has virtio0 which isn't configured on the VM`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_RemoveNet_DetachesNic(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	net = {
+		bridge = "vmbr0"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "net.bridge", "vmbr0"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckNoResourceAttr("proxmox_vm.test", "net"),
+					testCheckVMConfigKeyNotInPve(&vm, "net0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CreateCloneOfTemplate(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node = "pve"
+	name = "m-o"
+	description = "Microbe-Obliterator"
+	
+	clone      = "200"
+	clone_full = false
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
+					testCheckVMValuesInPve(&vm, types.StringValue("pve"), types.Int64Value(100), types.StringValue("m-o"), types.StringValue("Microbe-Obliterator"), types.Int64Value(1), types.Int64Value(1), types.Int64Value(16)),
+					testCheckVMStatusInPve(&vm, "running"),
+					testCheckVMIsCloneOf(&vm, template),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "node", "pve"),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "vmid", "100"),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "name", "m-o"),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "description", "Microbe-Obliterator"),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "status", "running"),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "clone", "200"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node = "pve"
+	name = "m-o"
+	description = "Microbe-Obliterator"
+	
+	clone      = "200"
+	clone_full = false
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
 					testCheckVMValuesInPve(&vm, types.StringValue("pve"), types.Int64Value(100), types.StringValue("m-o"), types.StringValue("Microbe-Obliterator"), types.Int64Value(1), types.Int64Value(1), types.Int64Value(16)),
 					testCheckVMStatusInPve(&vm, "running"),
 					testCheckVMIsCloneOf(&vm, template),
@@ -546,6 +3282,561 @@ resource "proxmox_vm" "test_clone" {
 	})
 }
 
+func TestAccVMResource_MinimalClone_InheritsFromTemplate(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 32, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	config := providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node       = "pve"
+	clone      = "200"
+	clone_full = false
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
+					testCheckVMIsCloneOf(&vm, template),
+					// the template's memory rather than the default
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "memory", "32"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CloneAndGrowDisk(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node = "pve"
+	name = "m-o"
+
+	clone      = "200"
+	clone_full = false
+
+	virtio0 = {
+		media   = "disk"
+		size    = 20
+		storage = "local"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
+					testCheckVMIsCloneOf(&vm, template),
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio0", types.StringValue("local"), types.StringValue("20G")),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "virtio0.size", "20"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node = "pve"
+	name = "m-o"
+
+	clone      = "200"
+	clone_full = false
+
+	virtio0 = {
+		media   = "disk"
+		size    = 20
+		storage = "local"
+	}
+}
+`,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CloneAndShrinkDisk_Error(t *testing.T) {
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		// the shrink is caught before cloning, not leaving a clone behind
+		CheckDestroy: testCheckNoVMNamedInPve("m-o"),
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node = "pve"
+	name = "m-o"
+
+	clone = "200"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 2
+		storage = "local"
+	}
+}
+`,
+				ExpectError: regexp.MustCompile(`Disk Shrink Not Supported`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CloneTemplateWithoutCPU_PlanValuesWin(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	// the template has neither sockets nor cores in its config
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	config := providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node = "pve"
+	name = "m-o"
+
+	clone      = "200"
+	clone_full = false
+
+	sockets = 2
+	cores   = 2
+	memory  = 32
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
+					testCheckVMValuesInPve(&vm, types.StringValue("pve"), types.Int64Value(100), types.StringValue("m-o"), types.StringNull(), types.Int64Value(2), types.Int64Value(2), types.Int64Value(32)),
+					testCheckVMIsCloneOf(&vm, template),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "sockets", "2"),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "cores", "2"),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "memory", "32"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CloneWithMACAddress(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	// give the template a NIC of its own, the clone gets a new MAC for it when cloned
+	ref := pveapi.NewVmRef(int(template.VMID.ValueInt64()))
+	ref.SetNode(template.Node.ValueString())
+	ref.SetVmType("qemu")
+	_, err = testutil.TestClient.SetVmConfig(ref, map[string]any{"net0": "virtio=bc:24:11:00:00:01,bridge=vmbr0"})
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+
+	config := providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node = "pve"
+	name = "m-o"
+
+	clone      = "200"
+	clone_full = false
+
+	net = {
+		bridge      = "vmbr0"
+		mac_address = "bc:24:11:6f:9e:d5"
+	}
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
+					testCheckVMIsCloneOf(&vm, template),
+					testCheckVMNetValuesInPve(ctx, &vm, types.StringValue("vmbr0"), types.StringValue("bc:24:11:6f:9e:d5")),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "net.mac_address", "bc:24:11:6f:9e:d5"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_FullCloneOntoStorage(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	config := providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node = "pve"
+
+	clone         = "200"
+	clone_storage = "local-lvm"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
+					// a full clone has its own disks, not the template's
+					testCheckVMStorageValuesInPve(ctx, &vm, "virtio0", types.StringValue("local-lvm"), types.StringValue("5G")),
+					testCheckVMConfigKeyNotInPve(&vm, "unused0"),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "clone_storage", "local-lvm"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CloneOntoMissingStorage_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	clone         = "200"
+	clone_storage = "nonexistent"
+}
+`,
+				ExpectError: regexp.MustCompile(`Storage Not Found`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CloneStorageWithoutClone_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	clone_storage = "local-lvm"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_LinkedAndFullClone(t *testing.T) {
+	var linked, full vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	config := providerConfig + `
+resource "proxmox_vm" "linked" {
+	node = "pve"
+
+	clone      = "200"
+	clone_full = false
+}
+
+resource "proxmox_vm" "full" {
+	node = "pve"
+
+	clone      = "200"
+	clone_full = true
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.linked", &linked),
+					testCheckVMExistsInPve(ctx, "proxmox_vm.full", &full),
+					// the linked clone's disk is based on the template's, which is left as unused0 when the disk is replaced
+					testCheckVMIsCloneOf(&linked, template),
+					testCheckVMConfigKeyNotInPve(&full, "unused0"),
+					resource.TestCheckResourceAttr("proxmox_vm.linked", "clone_full", "false"),
+					resource.TestCheckResourceAttr("proxmox_vm.full", "clone_full", "true"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CloneOfTemplateAndVM_PicksLinkedOrFull(t *testing.T) {
+	var ofTemplate, ofVM, source vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	config := providerConfig + `
+resource "proxmox_vm" "source" {
+	node   = "pve"
+	vmid   = 150
+	status = "running"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 1
+		storage = "local-lvm"
+	}
+}
+
+resource "proxmox_vm" "of_template" {
+	node = "pve"
+
+	clone = "200"
+}
+
+resource "proxmox_vm" "of_vm" {
+	node = "pve"
+
+	clone = tostring(proxmox_vm.source.vmid)
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.source", &source),
+					testCheckVMExistsInPve(ctx, "proxmox_vm.of_template", &ofTemplate),
+					testCheckVMExistsInPve(ctx, "proxmox_vm.of_vm", &ofVM),
+					testCheckVMIsCloneOf(&ofTemplate, template),
+					resource.TestCheckNoResourceAttr("proxmox_vm.of_template", "clone_full"),
+					resource.TestCheckNoResourceAttr("proxmox_vm.of_vm", "clone_full"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_LinkedCloneOfNonTemplate_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "source" {
+	node = "pve"
+	vmid = 150
+}
+
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	clone      = tostring(proxmox_vm.source.vmid)
+	clone_full = false
+}
+`,
+				ExpectError: regexp.MustCompile(`linked clones can only be made from templates`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CloneStorageOnLinkedClone_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+
+	clone         = "200"
+	clone_full    = false
+	clone_storage = "local-lvm"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CloneFromSnapshot(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	source, err := createVMWithSnapshotInPve(ctx, "Test-Source-01", 200, "pve", "before")
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(source)
+	defer cleanUpFunc()
+
+	config := providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node = "pve"
+
+	clone          = "200"
+	clone_snapshot = "before"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "clone_snapshot", "before"),
+					// the clone is of the snapshot, from before the description was set
+					testCheckVMConfigKeyNotInPve(&vm, "description"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccVMResource_CloneFromMissingSnapshot_CausesError(t *testing.T) {
+	ctx := testutil.GetTestLoggingContext()
+
+	source, err := createVMWithSnapshotInPve(ctx, "Test-Source-01", 200, "pve", "before")
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(source)
+	defer cleanUpFunc()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node = "pve"
+
+	clone          = "200"
+	clone_snapshot = "nonexistent"
+}
+`,
+				ExpectError: regexp.MustCompile(`Snapshot Not Found`),
+			},
+		},
+	})
+}
+
 func TestAccVMResource_CreateCloneOfTemplateByName(t *testing.T) {
 	var vm vmResourceModel
 
@@ -569,7 +3860,8 @@ resource "proxmox_vm" "test_clone" {
 	name = "m-o"
 	description = "Microbe-Obliterator"
 	
-	clone = "Test-Template-01"
+	clone      = "Test-Template-01"
+	clone_full = false
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
@@ -589,6 +3881,63 @@ resource "proxmox_vm" "test_clone" {
 	})
 }
 
+func TestAccVMResource_CreateCloneWithCreateTimeout(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node = "pve"
+	name = "m-o"
+
+	clone      = "200"
+	clone_full = false
+
+	timeouts = {
+		create = "not-a-duration"
+	}
+}
+`,
+				ExpectError: regexp.MustCompile(`value must be a duration`),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test_clone" {
+	node = "pve"
+	name = "m-o"
+
+	clone      = "200"
+	clone_full = false
+
+	timeouts = {
+		create = "10m"
+	}
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test_clone", &vm),
+					testCheckVMIsCloneOf(&vm, template),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "vmid", "100"),
+					resource.TestCheckResourceAttr("proxmox_vm.test_clone", "timeouts.create", "10m"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccVMResource_CreateAndUpdateToClone_ShouldBeRecreatedAsClone(t *testing.T) {
 	var vm vmResourceModel
 
@@ -631,7 +3980,8 @@ resource "proxmox_vm" "test_to_be_clone" {
 	name = "m-o"
 	description = "Microbe-Obliterator"
 
-	clone = "200"
+	clone      = "200"
+	clone_full = false
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
@@ -682,7 +4032,8 @@ resource "proxmox_vm" "test_clone" {
 	name = "m-o"
 	description = "Microbe-Obliterator"
 
-	clone = "200"
+	clone      = "200"
+	clone_full = false
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
@@ -705,7 +4056,8 @@ resource "proxmox_vm" "test_clone" {
 	name = "m-o"
 	description = "Microbe-Obliterator"
 
-	clone = "201"
+	clone      = "201"
+	clone_full = false
 }
 `,
 				Check: resource.ComposeTestCheckFunc(
@@ -748,7 +4100,8 @@ resource "proxmox_vm" "test_clone" {
 	name = "m-o"
 	description = "Microbe-Obliterator"
 	
-	clone = "200"
+	clone      = "200"
+	clone_full = false
 
 	memory = 32
 	cores = 1
@@ -776,7 +4129,8 @@ resource "proxmox_vm" "test_clone" {
 	name = "m-o"
 	description = "Microbe-Obliterator"
 	
-	clone = "200"
+	clone      = "200"
+	clone_full = false
 
 	memory = 40
 	cores = 2
@@ -976,8 +4330,61 @@ resource "proxmox_vm" "test" {
 `,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
-					testCheckVMValuesInPve(&vm, types.StringValue("pve"), types.Int64Value(140), types.StringValue("wall-e"), types.StringNull(), types.Int64Value(1), types.Int64Value(1), types.Int64Value(16)),
-					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "140"),
+					testCheckVMValuesInPve(&vm, types.StringValue("pve"), types.Int64Value(140), types.StringValue("wall-e"), types.StringNull(), types.Int64Value(1), types.Int64Value(1), types.Int64Value(16)),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "140"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMResource_UnconfigureVMIDThenDestroyOutOfBand_IsRecreatedWithNextFreeID(t *testing.T) {
+	var vm vmResourceModel
+
+	ctx := testutil.GetTestLoggingContext()
+
+	config := providerConfig + `
+resource "proxmox_vm" "test" {
+	node   = "pve"
+	name   = "wall-e"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node   = "pve"
+	name   = "wall-e"
+	vmid   = 140
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "140"),
+				),
+			},
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "140"),
+				),
+			},
+			{
+				PreConfig: destroyVMInPve(&vm),
+				Config:    config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm.test", plancheck.ResourceActionCreate),
+						plancheck.ExpectUnknownValue("proxmox_vm.test", tfjsonpath.New("vmid")),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testCheckVMExistsInPve(ctx, "proxmox_vm.test", &vm),
+					testCheckVMValuesInPve(&vm, types.StringValue("pve"), types.Int64Value(100), types.StringValue("wall-e"), types.StringNull(), types.Int64Value(1), types.Int64Value(1), types.Int64Value(16)),
+					resource.TestCheckResourceAttr("proxmox_vm.test", "vmid", "100"),
 				),
 			},
 		},
@@ -1058,7 +4465,7 @@ func testCheckVMValuesInPve(r *vmResourceModel, node basetypes.StringValue, vmid
 	}
 }
 
-func testCheckVMStorageValuesInPve(ctx context.Context, r *vmResourceModel, endpoint string, storage basetypes.StringValue, size basetypes.Int64Value) resource.TestCheckFunc {
+func testCheckVMStorageValuesInPve(ctx context.Context, r *vmResourceModel, endpoint string, storage basetypes.StringValue, size basetypes.StringValue) resource.TestCheckFunc {
 	re := regexp.MustCompile(`^(virtio)(\d+)`)
 	return func(_ *terraform.State) error {
 		err := gomega.InterceptGomegaFailure(func() {
@@ -1066,17 +4473,36 @@ func testCheckVMStorageValuesInPve(ctx context.Context, r *vmResourceModel, endp
 			if m == nil {
 				panic("Unable to parse endpoint " + endpoint)
 			}
-			if m[1] == "virtio" && m[2] == "0" {
-				gomega.Expect(r.Virtio0.IsNull()).To(gomega.BeFalseBecause("virtio0 should not be null"))
-
-				var dm virtioModel
-				diags := r.Virtio0.As(ctx, &dm, basetypes.ObjectAsOptions{})
-				if diags.HasError() {
-					panic("error when reading virtio0 from resource model")
-				}
-				gomega.Expect(dm.Storage).To(gomega.Equal(storage))
-				gomega.Expect(dm.Size).To(gomega.Equal(size))
+			n, _ := strconv.Atoi(m[2])
+			disk := r.virtioDisks()[n]
+			gomega.Expect(disk.IsNull()).To(gomega.BeFalseBecause(endpoint + " should not be null"))
+
+			var dm virtioModel
+			diags := disk.As(ctx, &dm, basetypes.ObjectAsOptions{})
+			if diags.HasError() {
+				panic("error when reading " + endpoint + " from resource model")
+			}
+			gomega.Expect(dm.Storage).To(gomega.Equal(storage))
+			gomega.Expect(diskSizeKiB(dm.Size.ValueString())).To(gomega.Equal(diskSizeKiB(size.ValueString())))
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func testCheckVMVirtioBackupInPve(ctx context.Context, r *vmResourceModel, backup bool) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		err := gomega.InterceptGomegaFailure(func() {
+			gomega.Expect(r.Virtio0.IsNull()).To(gomega.BeFalseBecause("virtio0 should not be null"))
+			var dm virtioModel
+			diags := r.Virtio0.As(ctx, &dm, basetypes.ObjectAsOptions{})
+			if diags.HasError() {
+				panic("error when reading virtio0 from resource model")
 			}
+			gomega.Expect(dm.Backup).To(gomega.Equal(types.BoolValue(backup)))
 		})
 		if err != nil {
 			return err
@@ -1106,6 +4532,86 @@ func testCheckVMNetValuesInPve(ctx context.Context, r *vmResourceModel, bridge b
 	}
 }
 
+func testCheckVMConfigValueInPve(r *vmResourceModel, key string, value any) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
+		ref.SetNode(r.Node.ValueString())
+		ref.SetVmType("qemu")
+
+		config, err := testutil.TestClient.GetVmConfig(ref)
+		if err != nil {
+			return err
+		}
+
+		err = gomega.InterceptGomegaFailure(func() {
+			gomega.Expect(config[key]).To(gomega.Equal(value))
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func testCheckVMSSHKeysInPve(r *vmResourceModel, keys string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
+		ref.SetNode(r.Node.ValueString())
+		ref.SetVmType("qemu")
+
+		config, err := testutil.TestClient.GetVmConfig(ref)
+		if err != nil {
+			return err
+		}
+
+		encoded, ok := config["sshkeys"].(string)
+		if !ok {
+			return fmt.Errorf("sshkeys in VM config was not a string but %T", config["sshkeys"])
+		}
+		decoded, err := url.PathUnescape(encoded)
+		if err != nil {
+			return err
+		}
+
+		err = gomega.InterceptGomegaFailure(func() {
+			gomega.Expect(strings.TrimRight(decoded, "\n")).To(gomega.Equal(keys))
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// testCheckVMRebootedInPve checks that the VM has been rebooted through the reboot API, and never
+// stopped.
+func testCheckVMRebootedInPve(r *vmResourceModel) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		tasks, err := testutil.TestClient.GetItemListInterfaceArray(fmt.Sprintf("/nodes/%s/tasks?vmid=%d", r.Node.ValueString(), r.VMID.ValueInt64()))
+		if err != nil {
+			return err
+		}
+
+		statuses := map[string]string{}
+		for _, t := range tasks {
+			task := t.(map[string]any)
+			statuses[task["type"].(string)], _ = task["status"].(string)
+		}
+
+		err = gomega.InterceptGomegaFailure(func() {
+			gomega.Expect(statuses).To(gomega.HaveKeyWithValue("qmreboot", "OK"), "VM should have been rebooted")
+			gomega.Expect(statuses).ToNot(gomega.HaveKey("qmstop"), "VM should not have been stopped")
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
 func testCheckVMStatusInPve(r *vmResourceModel, status string) resource.TestCheckFunc {
 	return func(_ *terraform.State) error {
 		err := gomega.InterceptGomegaFailure(func() {
@@ -1121,6 +4627,34 @@ func testCheckVMStatusInPve(r *vmResourceModel, status string) resource.TestChec
 	}
 }
 
+// testCheckVMPidInPve records the pid of the QEMU process of the VM on first use and checks that it's
+// unchanged from then on, i.e. that the VM hasn't been restarted.
+func testCheckVMPidInPve(r *vmResourceModel, pid *any) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
+		ref.SetNode(r.Node.ValueString())
+		ref.SetVmType("qemu")
+
+		state, err := testutil.TestClient.GetVmState(ref)
+		if err != nil {
+			return err
+		}
+		if *pid == nil {
+			*pid = state["pid"]
+			return nil
+		}
+
+		err = gomega.InterceptGomegaFailure(func() {
+			gomega.Expect(state["pid"]).To(gomega.Equal(*pid), "VM should not have been restarted")
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
 func startVMInPve(r *vmResourceModel) func() {
 	return func() {
 		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
@@ -1188,6 +4722,56 @@ func createTemplateInPve(ctx context.Context, name string, vmid int, node string
 	return &vm, nil
 }
 
+// createVMWithSnapshotInPve creates a VM with a snapshot, after which the VM's description is set so
+// that it differs from the snapshot. Templates can't have snapshots so this is a regular VM.
+func createVMWithSnapshotInPve(ctx context.Context, name string, vmid int, node string, snapshot string) (*vmResourceModel, error) {
+	ref := pveapi.NewVmRef(vmid)
+	ref.SetNode(node)
+
+	config := pveapi.ConfigQemu{}
+	config.Name = name
+	config.Memory = 16
+
+	// snapshots need storage that supports them, unlike the raw disks on local of the templates
+	config.Disks = &pveapi.QemuStorages{
+		VirtIO: &pveapi.QemuVirtIODisks{
+			Disk_0: &pveapi.QemuVirtIOStorage{
+				Disk: &pveapi.QemuVirtIODisk{
+					Storage:         "local-lvm",
+					SizeInKibibytes: pveapi.QemuDiskSize(1024 * 1024),
+				},
+			},
+		},
+	}
+
+	err := config.Create(ref, testutil.TestClient)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = testutil.TestClient.StopVm(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = testutil.TestClient.CreateQemuSnapshot(ref, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = testutil.TestClient.SetVmConfig(ref, map[string]any{"description": "after snapshot"})
+	if err != nil {
+		return nil, err
+	}
+
+	var vm vmResourceModel
+	err = UpdateVMResourceModelFromAPI(ctx, vmid, testutil.TestClient, &vm, VMStateEverything)
+	if err != nil {
+		return nil, err
+	}
+	return &vm, nil
+}
+
 func setVMSocketsInPve(r *vmResourceModel, sockets int) func() {
 	return func() {
 		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
@@ -1272,6 +4856,93 @@ func setVMMemoryInPve(r *vmResourceModel, memory int) func() {
 	}
 }
 
+func setVMNetOptionInPve(r *vmResourceModel, key string, value string) func() {
+	return func() {
+		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
+		ref.SetNode(r.Node.ValueString())
+
+		config, err := pveapi.NewConfigQemuFromApi(ref, testutil.TestClient)
+		if err != nil {
+			panic("Unexpected error when test setting VM net option, reading config from API resulted in error: " + err.Error())
+		}
+		config.QemuNetworks[0][key] = value
+		_, err = config.Update(false, ref, testutil.TestClient)
+		if err != nil {
+			panic("Unexpected error when test setting VM net option, updating config in API resulted in error: " + err.Error())
+		}
+	}
+}
+
+func testCheckVMConfigKeyNotInPve(r *vmResourceModel, key string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
+		ref.SetNode(r.Node.ValueString())
+		ref.SetVmType("qemu")
+
+		config, err := testutil.TestClient.GetVmConfig(ref)
+		if err != nil {
+			return err
+		}
+
+		err = gomega.InterceptGomegaFailure(func() {
+			gomega.Expect(config).NotTo(gomega.HaveKey(key))
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func testCheckVMNetOptionInPve(r *vmResourceModel, key string, value any) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
+		ref.SetNode(r.Node.ValueString())
+
+		config, err := pveapi.NewConfigQemuFromApi(ref, testutil.TestClient)
+		if err != nil {
+			return err
+		}
+
+		err = gomega.InterceptGomegaFailure(func() {
+			if value == nil {
+				gomega.Expect(config.QemuNetworks[0]).NotTo(gomega.HaveKey(key))
+			} else {
+				gomega.Expect(config.QemuNetworks[0][key]).To(gomega.Equal(value))
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func resizeVMDiskInPve(r *vmResourceModel, disk string, size string) func() {
+	return func() {
+		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
+		ref.SetNode(r.Node.ValueString())
+		ref.SetVmType("qemu")
+
+		_, err := testutil.TestClient.ResizeQemuDiskRaw(ref, disk, size)
+		if err != nil {
+			panic("Unexpected error when test resizing VM disk, resizing in API resulted in error: " + err.Error())
+		}
+	}
+}
+
+func testCheckNoVMNamedInPve(name string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		vmrs, _ := testutil.TestClient.GetVmRefsByName(name)
+		if len(vmrs) > 0 {
+			return fmt.Errorf("expected no VM named %s, found VM %d", name, vmrs[0].VmId())
+		}
+		return nil
+	}
+}
+
 func destroyVMInPve(r *vmResourceModel) func() {
 	return func() {
 		ref := pveapi.NewVmRef(int(r.VMID.ValueInt64()))
@@ -1288,3 +4959,75 @@ func destroyVMInPve(r *vmResourceModel) func() {
 		}
 	}
 }
+
+func createPoolInPve(poolid string) (func(), error) {
+	err := testutil.TestClient.CreatePool(poolid, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		err := testutil.TestClient.DeletePool(poolid)
+		if err != nil {
+			panic("Failed to delete pool during test step: " + err.Error())
+		}
+	}, nil
+}
+
+// testCheckGuestPoolInPve checks the pool PVE lists the guest in, pool "" meaning it isn't in any.
+func testCheckGuestPoolInPve(vmid *basetypes.Int64Value, pool string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		guests, err := pveapi.ListGuests(testutil.TestClient)
+		if err != nil {
+			return err
+		}
+
+		for _, g := range guests {
+			if int64(g.Id) != vmid.ValueInt64() {
+				continue
+			}
+			return gomega.InterceptGomegaFailure(func() {
+				gomega.Expect(g.Pool).To(gomega.Equal(pool))
+			})
+		}
+
+		return fmt.Errorf("guest %d not found", vmid.ValueInt64())
+	}
+}
+
+func TestAccVMResource_CloneToTakenVMID_CausesError(t *testing.T) {
+	ctx := testutil.GetTestLoggingContext()
+
+	template, err := createTemplateInPve(ctx, "Test-Template-01", 200, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(template)
+	defer cleanUpFunc()
+
+	taken, err := createTemplateInPve(ctx, "Test-Template-02", 201, "pve", 16, 5)
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpTakenFunc := destroyVMInPve(taken)
+	defer cleanUpTakenFunc()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	vmid = 201
+
+	clone = "200"
+}
+`,
+				ExpectError: regexp.MustCompile(`A guest with ID 201 already exists`),
+			},
+		},
+	})
+}
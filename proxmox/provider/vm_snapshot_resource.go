@@ -0,0 +1,426 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+var (
+	_ resource.Resource                = &vmSnapshotResource{}
+	_ resource.ResourceWithConfigure   = &vmSnapshotResource{}
+	_ resource.ResourceWithImportState = &vmSnapshotResource{}
+)
+
+func NewVMSnapshotResource() resource.Resource {
+	return &vmSnapshotResource{}
+}
+
+type vmSnapshotResource struct {
+	client *pveapi.Client
+}
+
+type vmSnapshotResourceModel struct {
+	Node        types.String `tfsdk:"node"`
+	VMID        types.Int64  `tfsdk:"vmid"`
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	VMState     types.Bool   `tfsdk:"vmstate"`
+	FreezeFS    types.Bool   `tfsdk:"freeze_fs"`
+}
+
+// snapshotNameRe is what PVE accepts as snapshot name, "current" is taken by the guest's current state.
+var snapshotNameRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_\-]{2,39}$`)
+
+var guestNotFoundRe = regexp.MustCompile(`vm '\d+' not found`)
+
+func (*vmSnapshotResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_vm_snapshot"
+}
+
+func (*vmSnapshotResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "This resource manages a snapshot of a Proxmox VM or LXC.",
+		Attributes: map[string]schema.Attribute{
+			"node": schema.StringAttribute{
+				Description: "The cluster node the guest is on, looked up from vmid.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"vmid": schema.Int64Attribute{
+				Description: "The ID of the VM or LXC to snapshot.",
+				Required:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The name of the snapshot, 3-40 characters starting with a letter. Changing it makes a new snapshot.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(snapshotNameRe, "must be 3-40 letters, digits, _ or -, starting with a letter"),
+					stringvalidator.NoneOf("current"),
+				},
+			},
+			"description": schema.StringAttribute{
+				Description: "Description of the snapshot, can be changed in place.",
+				Optional:    true,
+			},
+			"vmstate": schema.BoolAttribute{
+				Description: "Include the RAM of a running VM in the snapshot. Not supported for LXC.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"freeze_fs": schema.BoolAttribute{
+				Description: "Freeze the guest's filesystems through the QEMU Guest Agent while the snapshot is taken, for application-consistent snapshots. Only done for running VMs with the agent enabled and responding, and not when vmstate is set. Only affects creating the snapshot.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+		},
+	}
+}
+
+func (r *vmSnapshotResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*proxmoxProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			fmt.Sprintf("Expected %T, got: %T. Please report this to the provider developers.", data, req.ProviderData),
+		)
+		return
+	}
+
+	r.client = data.client
+}
+
+func (r *vmSnapshotResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan vmSnapshotResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vmr := pveapi.NewVmRef(int(plan.VMID.ValueInt64()))
+	err := r.client.CheckVmRef(vmr)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("vmid"),
+			"Error Creating Snapshot",
+			fmt.Sprintf("Could not look up guest %d, unexpected error: %s", vmr.VmId(), err.Error()),
+		)
+		return
+	}
+
+	if plan.VMState.ValueBool() && vmr.GetVmType() == vmTypeLxc {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("vmstate"),
+			"Error Creating Snapshot",
+			fmt.Sprintf("Guest %d is an LXC, which can't have its RAM included in snapshots.", vmr.VmId()),
+		)
+		return
+	}
+
+	params := map[string]any{"snapname": plan.Name.ValueString()}
+	if !plan.Description.IsNull() {
+		params["description"] = plan.Description.ValueString()
+	}
+	if plan.VMState.ValueBool() {
+		params["vmstate"] = true
+	}
+
+	// with RAM included the snapshot is consistent as is
+	var thaw func() diag.Diagnostics
+	if plan.FreezeFS.ValueBool() && !plan.VMState.ValueBool() && vmr.GetVmType() == vmTypeQemu {
+		thaw, diags = freezeGuestFS(ctx, r.client, vmr)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	tflog.Trace(ctx, fmt.Sprintf("Creating snapshot %s of guest %d", plan.Name.ValueString(), vmr.VmId()))
+	body, err := r.client.CreateItemReturnStatus(params, snapshotURL(vmr, ""))
+	if err == nil {
+		var upid string
+		upid, err = upidFromResponse(body)
+		if err == nil {
+			err = waitForTask(ctx, r.client, upid, time.Duration(r.client.TaskTimeout)*time.Second)
+		}
+	}
+	if thaw != nil {
+		resp.Diagnostics.Append(thaw()...)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Creating Snapshot",
+			fmt.Sprintf("Could not create snapshot %s of guest %d, unexpected error: %s", plan.Name.ValueString(), vmr.VmId(), err.Error()),
+		)
+		return
+	}
+
+	plan.Node = types.StringValue(vmr.Node())
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *vmSnapshotResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state vmSnapshotResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vmr := pveapi.NewVmRef(int(state.VMID.ValueInt64()))
+	err := r.client.CheckVmRef(vmr)
+	if err != nil && guestNotFoundRe.MatchString(err.Error()) {
+		tflog.Trace(ctx, fmt.Sprintf("Guest %d of snapshot %s doesn't exist", vmr.VmId(), state.Name.ValueString()))
+		resp.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Snapshot",
+			fmt.Sprintf("Could not look up guest %d, unexpected error: %s", vmr.VmId(), err.Error()),
+		)
+		return
+	}
+
+	snapshot, err := findSnapshot(r.client, vmr, state.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Snapshot",
+			fmt.Sprintf("Could not list snapshots of guest %d, unexpected error: %s", vmr.VmId(), err.Error()),
+		)
+		return
+	}
+	if snapshot == nil {
+		tflog.Trace(ctx, fmt.Sprintf("Snapshot %s of guest %d doesn't exist", state.Name.ValueString(), vmr.VmId()))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Node = types.StringValue(vmr.Node())
+	if description, _ := snapshot["description"].(string); description != "" {
+		// PVE adds a trailing newline
+		state.Description = types.StringValue(strings.TrimSuffix(description, "\n"))
+	} else {
+		state.Description = types.StringNull()
+	}
+	vmstate, _ := snapshot["vmstate"].(float64)
+	state.VMState = types.BoolValue(vmstate == 1)
+	if state.FreezeFS.IsNull() {
+		// imported, PVE doesn't know how the snapshot was taken
+		state.FreezeFS = types.BoolValue(true)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *vmSnapshotResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan vmSnapshotResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// only description can change without replacing the snapshot
+	vmr := pveapi.NewVmRef(int(plan.VMID.ValueInt64()))
+	err := r.client.CheckVmRef(vmr)
+	if err == nil {
+		err = r.client.Put(map[string]any{"description": plan.Description.ValueString()}, snapshotURL(vmr, plan.Name.ValueString())+"/config")
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Updating Snapshot",
+			fmt.Sprintf("Could not update description of snapshot %s of guest %d, unexpected error: %s", plan.Name.ValueString(), vmr.VmId(), err.Error()),
+		)
+		return
+	}
+
+	plan.Node = types.StringValue(vmr.Node())
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *vmSnapshotResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state vmSnapshotResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+
+	vmr := pveapi.NewVmRef(int(state.VMID.ValueInt64()))
+	err := r.client.CheckVmRef(vmr)
+	if err != nil && guestNotFoundRe.MatchString(err.Error()) {
+		tflog.Trace(ctx, fmt.Sprintf("Can't delete snapshot %s, guest %d doesn't exist", state.Name.ValueString(), vmr.VmId()))
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting Snapshot",
+			fmt.Sprintf("Could not look up guest %d, unexpected error: %s", vmr.VmId(), err.Error()),
+		)
+		return
+	}
+
+	snapshot, err := findSnapshot(r.client, vmr, state.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting Snapshot",
+			fmt.Sprintf("Could not list snapshots of guest %d, unexpected error: %s", vmr.VmId(), err.Error()),
+		)
+		return
+	}
+	if snapshot == nil {
+		tflog.Trace(ctx, fmt.Sprintf("Can't delete snapshot %s of guest %d, doesn't exist", state.Name.ValueString(), vmr.VmId()))
+		return
+	}
+
+	_, err = r.client.DeleteWithTask(snapshotURL(vmr, state.Name.ValueString()))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting Snapshot",
+			fmt.Sprintf("Could not delete snapshot %s of guest %d, unexpected error: %s", state.Name.ValueString(), vmr.VmId(), err.Error()),
+		)
+		return
+	}
+	tflog.Trace(ctx, fmt.Sprintf("Snapshot %s of guest %d deleted", state.Name.ValueString(), vmr.VmId()))
+}
+
+func (r *vmSnapshotResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	id, name, ok := strings.Cut(req.ID, "/")
+	vmid, err := strconv.Atoi(id)
+	if !ok || err != nil || vmid <= 0 || name == "" {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: <vmid>/<name>. Got: %q", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("vmid"), int64(vmid))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+}
+
+// snapshotURL is the API path of the snapshots of the guest, or of the named one if name isn't "".
+// vmr has to have been looked up.
+func snapshotURL(vmr *pveapi.VmRef, name string) string {
+	u := fmt.Sprintf("/nodes/%s/%s/%d/snapshot", vmr.Node(), vmr.GetVmType(), vmr.VmId())
+	if name != "" {
+		u += "/" + url.PathEscape(name)
+	}
+	return u
+}
+
+// findSnapshot returns the named snapshot of the guest as listed by PVE, or nil if there's no such
+// snapshot. vmr has to have been looked up.
+func findSnapshot(client *pveapi.Client, vmr *pveapi.VmRef, name string) (map[string]any, error) {
+	snapshots, err := client.GetItemListInterfaceArray(snapshotURL(vmr, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range snapshots {
+		if m, ok := s.(map[string]any); ok && m["name"] == name {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+// freezeGuestFS freezes the filesystems of the VM through the guest agent, if the agent is enabled and
+// responding, and returns the func thawing them again. PVE tries to do this itself when taking the
+// snapshot but only notes failures in the task log, so not freezing is a warning here, and the snapshot
+// is still taken crash-consistent. Not thawing is a warning too, it doesn't undo the snapshot. thaw is
+// nil if nothing was frozen.
+func freezeGuestFS(ctx context.Context, client *pveapi.Client, vmr *pveapi.VmRef) (thaw func() diag.Diagnostics, diags diag.Diagnostics) {
+	config, err := client.GetVmConfig(vmr)
+	if err != nil {
+		diags.AddWarning(
+			"Filesystems Not Frozen",
+			fmt.Sprintf("Could not read config of VM %d to check for the guest agent, the snapshot is only crash-consistent: %s", vmr.VmId(), err.Error()),
+		)
+		return nil, diags
+	}
+	if !agentEnabled(config) {
+		tflog.Trace(ctx, fmt.Sprintf("Guest agent not enabled for VM %d, not freezing filesystems", vmr.VmId()))
+		return nil, diags
+	}
+
+	// a stopped VM, or one without the agent installed, has nothing to freeze
+	if _, err := client.QemuAgentPing(vmr); err != nil {
+		tflog.Trace(ctx, fmt.Sprintf("Guest agent of VM %d not responding, not freezing filesystems: %s", vmr.VmId(), err.Error()))
+		return nil, diags
+	}
+
+	agentURL := fmt.Sprintf("/nodes/%s/qemu/%d/agent/", vmr.Node(), vmr.VmId())
+	err = client.Post(map[string]any{}, agentURL+"fsfreeze-freeze")
+	if err != nil {
+		diags.AddWarning(
+			"Filesystems Not Frozen",
+			fmt.Sprintf("Could not freeze filesystems of VM %d through the guest agent, the snapshot is only crash-consistent: %s", vmr.VmId(), err.Error()),
+		)
+		// a freeze can fail halfway, so thaw anyway
+	} else {
+		tflog.Trace(ctx, fmt.Sprintf("Froze filesystems of VM %d", vmr.VmId()))
+	}
+
+	return func() diag.Diagnostics {
+		var diags diag.Diagnostics
+		err := client.Post(map[string]any{}, agentURL+"fsfreeze-thaw")
+		if err != nil {
+			diags.AddWarning(
+				"Filesystems Not Thawed",
+				fmt.Sprintf("Could not thaw filesystems of VM %d after snapshotting it, the guest can't write to them until they are thawed (qm guest cmd %d fsfreeze-thaw). The snapshot itself was taken. Unexpected error: %s", vmr.VmId(), vmr.VmId(), err.Error()),
+			)
+			return diags
+		}
+		tflog.Trace(ctx, fmt.Sprintf("Thawed filesystems of VM %d", vmr.VmId()))
+		return diags
+	}, diags
+}
+
+// agentEnabled tells if the agent option in a VM config enables the guest agent, it's either a bare
+// boolean or a property string with enabled as the default key.
+func agentEnabled(config map[string]any) bool {
+	agent, _ := config["agent"].(string)
+	enabled, _, _ := strings.Cut(agent, ",")
+	enabled = strings.TrimPrefix(enabled, "enabled=")
+	return enabled == "1" || enabled == "true" || enabled == "yes" || enabled == "on"
+}
@@ -0,0 +1,283 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+	"github.com/mollstam/terraform-provider-proxmox/proxmox/provider/testutil"
+)
+
+func TestAccVMSnapshotResource_CreateAndUpdate(t *testing.T) {
+	ctx := testutil.GetTestLoggingContext()
+
+	vm, err := createVMWithSnapshotInPve(ctx, "Test-VM-01", 200, "pve", "existing")
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(vm)
+	defer cleanUpFunc()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm_snapshot" "test" {
+	vmid        = 200
+	name        = "before_upgrade"
+	description = "Before upgrade"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("proxmox_vm_snapshot.test", "node", "pve"),
+					resource.TestCheckResourceAttr("proxmox_vm_snapshot.test", "vmstate", "false"),
+					testCheckSnapshotInPve(200, "before_upgrade", true),
+				),
+			},
+			{
+				ResourceName:      "proxmox_vm_snapshot.test",
+				ImportState:       true,
+				ImportStateId:     "200/before_upgrade",
+				ImportStateVerify: true,
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm_snapshot" "test" {
+	vmid        = 200
+	name        = "before_upgrade"
+	description = "Before the upgrade"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("proxmox_vm_snapshot.test", "description", "Before the upgrade"),
+					testCheckSnapshotInPve(200, "before_upgrade", true),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_vm_snapshot" "test" {
+	vmid        = 200
+	name        = "after_upgrade"
+	description = "Before the upgrade"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSnapshotInPve(200, "before_upgrade", false),
+					testCheckSnapshotInPve(200, "after_upgrade", true),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMSnapshotResource_DeletedOutsideTerraform_IsRecreated(t *testing.T) {
+	ctx := testutil.GetTestLoggingContext()
+
+	vm, err := createVMWithSnapshotInPve(ctx, "Test-VM-01", 200, "pve", "existing")
+	if err != nil {
+		t.Error("Error during setup: " + err.Error())
+		return
+	}
+	cleanUpFunc := destroyVMInPve(vm)
+	defer cleanUpFunc()
+
+	config := providerConfig + `
+resource "proxmox_vm_snapshot" "test" {
+	vmid = 200
+	name = "before_upgrade"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  testCheckSnapshotInPve(200, "before_upgrade", true),
+			},
+			{
+				PreConfig: func() {
+					ref := pveapi.NewVmRef(200)
+					ref.SetNode("pve")
+					_, err := testutil.TestClient.DeleteQemuSnapshot(ref, "before_upgrade")
+					if err != nil {
+						panic("Failed to delete snapshot during test step: " + err.Error())
+					}
+				},
+				Config: config,
+				Check:  testCheckSnapshotInPve(200, "before_upgrade", true),
+			},
+		},
+	})
+}
+
+func TestAccVMSnapshotResource_AgentNotRunning_IsSnapshottedWithoutFreeze(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node   = "pve"
+	vmid   = 200
+	agent  = true
+	status = "running"
+
+	virtio0 = {
+		media   = "disk"
+		size    = 1
+		storage = "local-lvm"
+	}
+}
+
+resource "proxmox_vm_snapshot" "test" {
+	vmid = proxmox_vm.test.vmid
+	name = "before_upgrade"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("proxmox_vm_snapshot.test", "freeze_fs", "true"),
+					testCheckSnapshotInPve(200, "before_upgrade", true),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVMSnapshotResource_CreateAndUpdateOfLXC(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	vmid       = 200
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+
+resource "proxmox_vm_snapshot" "test" {
+	vmid        = proxmox_lxc.test.vmid
+	name        = "before_upgrade"
+	description = "Before upgrade"
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("proxmox_vm_snapshot.test", "node", "pve"),
+					resource.TestCheckResourceAttr("proxmox_vm_snapshot.test", "vmstate", "false"),
+					testCheckSnapshotInPve(200, "before_upgrade", true),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	vmid       = 200
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+
+resource "proxmox_vm_snapshot" "test" {
+	vmid        = proxmox_lxc.test.vmid
+	name        = "before_upgrade"
+	description = "Before the upgrade"
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("proxmox_vm_snapshot.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("proxmox_vm_snapshot.test", tfjsonpath.New("node"), knownvalue.StringExact("pve")),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("proxmox_vm_snapshot.test", "description", "Before the upgrade"),
+					testCheckSnapshotInPve(200, "before_upgrade", true),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	vmid       = 200
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+`,
+				Check: testCheckSnapshotInPve(200, "before_upgrade", false),
+			},
+		},
+	})
+}
+
+func TestAccVMSnapshotResource_VMStateOfLXC_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_lxc" "test" {
+	node       = "pve"
+	vmid       = 200
+	ostemplate = "local:vztmpl/alpine-3.18-default_20230607_amd64.tar.xz"
+}
+
+resource "proxmox_vm_snapshot" "test" {
+	vmid    = proxmox_lxc.test.vmid
+	name    = "before_upgrade"
+	vmstate = true
+}
+`,
+				ExpectError: regexp.MustCompile(`Guest 200 is an LXC`),
+			},
+		},
+	})
+}
+
+func TestAccVMSnapshotResource_InvalidName_CausesError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm_snapshot" "test" {
+	vmid = 200
+	name = "1 bad name"
+}
+`,
+				ExpectError: regexp.MustCompile(`must be 3-40 letters`),
+			},
+		},
+	})
+}
+
+func testCheckSnapshotInPve(vmid int, name string, exists bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		ref := pveapi.NewVmRef(vmid)
+		err := testutil.TestClient.CheckVmRef(ref)
+		if err != nil {
+			return err
+		}
+
+		snapshots, err := testutil.TestClient.GetItemListInterfaceArray(snapshotURL(ref, ""))
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, s := range snapshots {
+			if m, ok := s.(map[string]any); ok && m["name"] == name {
+				found = true
+			}
+		}
+		if found != exists {
+			return fmt.Errorf("expected snapshot %s of guest %d to exist: %t, got %t", name, vmid, exists, found)
+		}
+		return nil
+	}
+}
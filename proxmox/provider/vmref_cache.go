@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"sync"
+
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+// vmRefCache remembers which guest a name resolved to, so that cloning many VMs from the same
+// template by name only has to list the cluster resources once. Each configured provider has a
+// cache of its own, as aliased providers may point at different clusters.
+type vmRefCache struct {
+	mu      sync.Mutex
+	entries map[string]vmRefCacheEntry
+}
+
+type vmRefCacheEntry struct {
+	vmid   int
+	node   string
+	vmType string
+}
+
+func newVMRefCache() *vmRefCache {
+	return &vmRefCache{
+		entries: map[string]vmRefCacheEntry{},
+	}
+}
+
+// resolve returns a VmRef for the guest with the given name, only asking the API if the name has
+// not been resolved before. The second return value tells if the result came from the cache.
+func (c *vmRefCache) resolve(client *pveapi.Client, name string) (*pveapi.VmRef, bool, error) {
+	// hold the lock while asking the API so concurrent lookups of the same name wait for the first one
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[name]; ok {
+		return e.vmRef(), true, nil
+	}
+
+	vmr, err := client.GetVmRefByName(name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	e := vmRefCacheEntry{
+		vmid:   vmr.VmId(),
+		node:   vmr.Node(),
+		vmType: vmr.GetVmType(),
+	}
+	c.entries[name] = e
+
+	return e.vmRef(), false, nil
+}
+
+// invalidate forgets the resolution of name, e.g. after the template was found to be gone or moved.
+func (c *vmRefCache) invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, name)
+}
+
+// retryIfStale tells whether a failed clone of the guest resolved for name should be retried, which
+// is when the resolution came from the cache and the guest may have been removed or migrated since.
+// The entry is dropped so that the retry looks the name up again, and refreshed makes sure that only
+// happens once.
+func (c *vmRefCache) retryIfStale(name string, fromCache bool, refreshed *bool) bool {
+	if !fromCache || *refreshed {
+		return false
+	}
+	c.invalidate(name)
+	*refreshed = true
+	return true
+}
+
+// vmRef returns a fresh VmRef for each caller since the API client mutates them.
+func (e vmRefCacheEntry) vmRef() *pveapi.VmRef {
+	vmr := pveapi.NewVmRef(e.vmid)
+	vmr.SetNode(e.node)
+	vmr.SetVmType(e.vmType)
+	return vmr
+}
@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+// fakeGuestList fakes an API that only answers the cluster resources, listing a template named
+// tmpl whose location can be changed, as if it was recreated or migrated.
+type fakeGuestList struct {
+	mu       sync.Mutex
+	vmid     int
+	node     string
+	requests int
+}
+
+func (f *fakeGuestList) move(vmid int, node string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.vmid = vmid
+	f.node = node
+}
+
+func (f *fakeGuestList) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.URL.Path != "/api2/json/cluster/resources" {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	f.requests++
+	fmt.Fprintf(w, `{"data":[{"vmid":%d,"node":"%s","type":"qemu","name":"tmpl","template":1}]}`, f.vmid, f.node)
+}
+
+func (f *fakeGuestList) requestCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func newFakeGuestListClient(t *testing.T, f *fakeGuestList) *pveapi.Client {
+	pve := httptest.NewServer(f)
+	t.Cleanup(pve.Close)
+
+	client, err := pveapi.NewClient(pve.URL+"/api2/json", nil, "", nil, "", 300)
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %s", err)
+	}
+	return client
+}
+
+func expectVMRef(t *testing.T, vmr *pveapi.VmRef, vmid int, node string) {
+	t.Helper()
+	if vmr.VmId() != vmid || vmr.Node() != node || vmr.GetVmType() != "qemu" {
+		t.Errorf("expected qemu %d on %s, got %s %d on %s", vmid, node, vmr.GetVmType(), vmr.VmId(), vmr.Node())
+	}
+}
+
+func TestVMRefCache_Resolve(t *testing.T) {
+	f := &fakeGuestList{vmid: 300, node: "pve"}
+	client := newFakeGuestListClient(t, f)
+	c := newVMRefCache()
+
+	vmr, fromCache, err := c.resolve(client, "tmpl")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if fromCache {
+		t.Error("expected the first resolve to ask the API")
+	}
+	expectVMRef(t, vmr, 300, "pve")
+
+	// the API client mutates the VmRefs it's given, which mustn't change the cached entry
+	vmr.SetNode("pve2")
+
+	vmr, fromCache, err = c.resolve(client, "tmpl")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !fromCache {
+		t.Error("expected the second resolve to come from the cache")
+	}
+	expectVMRef(t, vmr, 300, "pve")
+
+	if n := f.requestCount(); n != 1 {
+		t.Errorf("expected the guests to be listed once, got %d", n)
+	}
+
+	if _, _, err := c.resolve(client, "missing"); err == nil {
+		t.Error("expected an error resolving a name no guest has")
+	}
+	if _, _, err := c.resolve(client, "missing"); err == nil {
+		t.Error("expected a failed resolve not to be cached")
+	}
+}
+
+func TestVMRefCache_Invalidate(t *testing.T) {
+	f := &fakeGuestList{vmid: 300, node: "pve"}
+	client := newFakeGuestListClient(t, f)
+	c := newVMRefCache()
+
+	if _, _, err := c.resolve(client, "tmpl"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	f.move(301, "pve2")
+	c.invalidate("tmpl")
+	// forgetting a name that was never resolved is fine
+	c.invalidate("missing")
+
+	vmr, fromCache, err := c.resolve(client, "tmpl")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if fromCache {
+		t.Error("expected resolve to ask the API after invalidate")
+	}
+	expectVMRef(t, vmr, 301, "pve2")
+}
+
+// TestVMRefCache_RetryIfStale follows what Create does when cloning from a cached template fails.
+func TestVMRefCache_RetryIfStale(t *testing.T) {
+	f := &fakeGuestList{vmid: 300, node: "pve"}
+	client := newFakeGuestListClient(t, f)
+	c := newVMRefCache()
+
+	// an earlier create resolved the template, which was then migrated
+	if _, _, err := c.resolve(client, "tmpl"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	f.move(300, "pve2")
+
+	refreshed := false
+	vmr, fromCache, err := c.resolve(client, "tmpl")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectVMRef(t, vmr, 300, "pve")
+
+	// cloning from the stale location fails
+	if !c.retryIfStale("tmpl", fromCache, &refreshed) {
+		t.Fatal("expected a failed clone of a cached template to be retried")
+	}
+
+	vmr, fromCache, err = c.resolve(client, "tmpl")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if fromCache {
+		t.Error("expected the retry to look the template up again")
+	}
+	expectVMRef(t, vmr, 300, "pve2")
+
+	// a clone failing for other reasons isn't retried again, neither with a fresh nor a cached lookup
+	if c.retryIfStale("tmpl", fromCache, &refreshed) {
+		t.Error("expected a clone of a freshly looked up template not to be retried")
+	}
+	if c.retryIfStale("tmpl", true, &refreshed) {
+		t.Error("expected a clone to only be retried once")
+	}
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	pveapi "github.com/mollstam/proxmox-api-go/proxmox"
+)
+
+var (
+	_ datasource.DataSource              = &vmsDataSource{}
+	_ datasource.DataSourceWithConfigure = &vmsDataSource{}
+)
+
+func NewVMsDataSource() datasource.DataSource {
+	return &vmsDataSource{}
+}
+
+type vmsDataSource struct {
+	client *pveapi.Client
+}
+
+type vmsDataSourceModel struct {
+	Node types.String      `tfsdk:"node"`
+	Tags types.Set         `tfsdk:"tags"`
+	VMs  []vmsDataSourceVM `tfsdk:"vms"`
+}
+
+type vmsDataSourceVM struct {
+	VMID   types.Int64  `tfsdk:"vmid"`
+	Name   types.String `tfsdk:"name"`
+	Node   types.String `tfsdk:"node"`
+	Type   types.String `tfsdk:"type"`
+	Status types.String `tfsdk:"status"`
+}
+
+func (*vmsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_vms"
+}
+
+func (*vmsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the guests, VMs and LXCs, in the cluster.",
+		Attributes: map[string]schema.Attribute{
+			"node": schema.StringAttribute{
+				Description: "Only list guests on this node.",
+				Optional:    true,
+			},
+			"tags": schema.SetAttribute{
+				Description: "Only list guests that have all of these tags.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators:  TagsValidators(),
+			},
+			"vms": schema.ListNestedAttribute{
+				Description: "The guests, ordered by vmid.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"vmid": schema.Int64Attribute{
+							Description: "The ID of the guest.",
+							Computed:    true,
+						},
+						"name": schema.StringAttribute{
+							Description: "The name of the guest.",
+							Computed:    true,
+						},
+						"node": schema.StringAttribute{
+							Description: "The node the guest is on.",
+							Computed:    true,
+						},
+						"type": schema.StringAttribute{
+							Description: "The type of guest, qemu for VMs or lxc.",
+							Computed:    true,
+						},
+						"status": schema.StringAttribute{
+							Description: "The status of the guest, e.g. running or stopped.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *vmsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*proxmoxProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data Type",
+			fmt.Sprintf("Expected %T, got: %T. Please report this to the provider developers.", data, req.ProviderData),
+		)
+		return
+	}
+
+	d.client = data.client
+}
+
+func (d *vmsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config vmsDataSourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var tags []string
+	if !config.Tags.IsNull() {
+		resp.Diagnostics.Append(config.Tags.ElementsAs(ctx, &tags, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	guests, err := pveapi.ListGuests(d.client)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Listing Guests",
+			"Could not list guests, unexpected error: "+err.Error(),
+		)
+		return
+	}
+	sort.Slice(guests, func(i, j int) bool { return guests[i].Id < guests[j].Id })
+
+	config.VMs = []vmsDataSourceVM{}
+	for _, g := range guests {
+		if !config.Node.IsNull() && g.Node != config.Node.ValueString() {
+			continue
+		}
+		if !hasAllTags(g.Tags, tags) {
+			continue
+		}
+
+		config.VMs = append(config.VMs, vmsDataSourceVM{
+			VMID:   types.Int64Value(int64(g.Id)),
+			Name:   types.StringValue(g.Name),
+			Node:   types.StringValue(g.Node),
+			Type:   types.StringValue(string(g.Type)),
+			Status: types.StringValue(g.Status),
+		})
+	}
+
+	diags = resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
+
+// hasAllTags tells if every tag in want is among the tags of a guest.
+func hasAllTags(tags []string, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, t := range tags {
+			if t == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccVMsDataSource_ListsCreatedVM(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "proxmox_vm" "test" {
+	node = "pve"
+	vmid = 150
+	name = "wall-e"
+	tags = ["listed"]
+}
+
+resource "proxmox_vm" "other" {
+	node = "pve"
+	vmid = 151
+	name = "eve"
+}
+
+data "proxmox_vms" "all" {
+	depends_on = [proxmox_vm.test, proxmox_vm.other]
+}
+
+data "proxmox_vms" "tagged" {
+	tags = ["listed"]
+
+	depends_on = [proxmox_vm.test, proxmox_vm.other]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("data.proxmox_vms.all", "vms.*", map[string]string{
+						"vmid":   "150",
+						"name":   "wall-e",
+						"node":   "pve",
+						"type":   "qemu",
+						"status": "running",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("data.proxmox_vms.all", "vms.*", map[string]string{
+						"vmid": "151",
+						"name": "eve",
+					}),
+					resource.TestCheckResourceAttr("data.proxmox_vms.tagged", "vms.#", "1"),
+					resource.TestCheckResourceAttr("data.proxmox_vms.tagged", "vms.0.vmid", "150"),
+				),
+			},
+		},
+	})
+}